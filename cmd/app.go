@@ -13,6 +13,7 @@ var (
 	topLevelModule = fx.Options(
 		fx.Provide(config.Get),
 		fx.Provide(engine.NewFactory),
+		fx.Provide(engine.NewDispatcher),
 		fx.Provide(logger.NewSlogLogger),
 		fx.Provide(client.NewDataStoreFactory),
 	)
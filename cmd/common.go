@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path"
 	"syscall"
 
 	"github.com/dagu-dev/dagu/internal/agent"
@@ -12,6 +13,7 @@ import (
 	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/internal/engine"
 	"github.com/dagu-dev/dagu/internal/persistence/client"
+	"github.com/dagu-dev/dagu/internal/pool"
 	"github.com/spf13/cobra"
 )
 
@@ -19,24 +21,37 @@ func execDAG(ctx context.Context, e engine.Engine, cmd *cobra.Command, args []st
 	params, err := cmd.Flags().GetString("params")
 	checkError(err)
 
-	loadedDAG, err := loadDAG(args[0], removeQuotes(params))
+	load := loadDAGForRun
+	if dry {
+		// No commands are executed in a dry-run, so the secrets are not resolved.
+		load = loadDAG
+	}
+	loadedDAG, err := load(args[0], removeQuotes(params))
 	checkError(err)
 
-	err = start(ctx, e, loadedDAG, dry)
+	requestId, _ := cmd.Flags().GetString("req")
+
+	err = start(ctx, e, loadedDAG, requestId, dry)
 	if err != nil {
 		log.Fatalf("Failed to start DAG: %v", err) // nolint // deep-exit
 	}
 }
 
-func start(ctx context.Context, e engine.Engine, d *dag.DAG, dry bool) error {
+func start(ctx context.Context, e engine.Engine, d *dag.DAG, requestId string, dry bool) error {
 	// TODO: remove this
 	ds := client.NewDataStoreFactory(config.Get())
 
-	a := agent.New(&agent.Config{DAG: d, Dry: dry}, e, ds)
+	a := agent.New(&agent.Config{DAG: d, Dry: dry, RequestId: requestId, Pools: pools()}, e, ds)
 	listenSignals(ctx, a)
 	return a.Run(ctx)
 }
 
+// pools returns the resource pools declared in the config.
+func pools() pool.Pools {
+	cfg := config.Get()
+	return pool.New(path.Join(cfg.DataDir, "pools"), cfg.Pools)
+}
+
 type signalListener interface {
 	Signal(os.Signal)
 }
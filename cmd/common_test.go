@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -43,6 +44,7 @@ func changeHomeDir(dir string) {
 
 type cmdTest struct {
 	args        []string
+	stdin       string
 	expectedOut []string
 }
 
@@ -54,6 +56,9 @@ func testRunCommand(t *testing.T, cmd *cobra.Command, test cmdTest) {
 
 	// Set arguments.
 	root.SetArgs(test.args)
+	if test.stdin != "" {
+		root.SetIn(strings.NewReader(test.stdin))
+	}
 
 	// Run the command.
 	out := withSpool(t, func() {
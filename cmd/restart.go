@@ -40,9 +40,9 @@ func restartCmd() *cobra.Command {
 			params := getPreviousExecutionParams(e, loadedDAG)
 
 			// Start the DAG with the same parameter.
-			loadedDAG, err = loadDAG(dagFile, params)
+			loadedDAG, err = loadDAGForRun(dagFile, params)
 			checkError(err)
-			cobra.CheckErr(start(cmd.Context(), e, loadedDAG, false))
+			cobra.CheckErr(start(cmd.Context(), e, loadedDAG, "", false))
 		},
 	}
 }
@@ -66,7 +66,7 @@ func stopRunningDAG(e engine.Engine, d *dag.DAG) error {
 		if st.Status != scheduler.StatusRunning {
 			return nil
 		}
-		checkError(e.Stop(d))
+		checkError(e.Stop(d, ""))
 		time.Sleep(time.Millisecond * 100)
 	}
 }
@@ -33,10 +33,10 @@ func retryCmd() *cobra.Command {
 			status, err := hs.FindByRequestId(f, reqID)
 			checkError(err)
 
-			loadedDAG, err := loadDAG(args[0], status.Status.Params)
+			loadedDAG, err := loadDAGForRun(args[0], status.Status.Params)
 			checkError(err)
 
-			a := agent.New(&agent.Config{DAG: loadedDAG, RetryTarget: status.Status}, e, df)
+			a := agent.New(&agent.Config{DAG: loadedDAG, RetryTarget: status.Status, Pools: pools()}, e, df)
 			ctx := cmd.Context()
 			listenSignals(ctx, a)
 			checkError(a.Run(ctx))
@@ -9,6 +9,7 @@ import (
 
 	"github.com/dagu-dev/dagu/internal/config"
 	"github.com/dagu-dev/dagu/internal/dag"
+	"github.com/dagu-dev/dagu/internal/persistence/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -74,6 +75,12 @@ func loadDAG(dagFile, params string) (d *dag.DAG, err error) {
 	return dag.Load(config.Get().BaseConfig, dagFile, params)
 }
 
+// loadDAGForRun loads the DAG to run it, resolving the values of the secrets.
+func loadDAGForRun(dagFile, params string) (d *dag.DAG, err error) {
+	ss := client.NewDataStoreFactory(config.Get()).NewSecretStore()
+	return dag.LoadForRun(config.Get().BaseConfig, dagFile, params, ss)
+}
+
 func getFlagString(cmd *cobra.Command, name, fallback string) string {
 	if s, _ := cmd.Flags().GetString(name); s != "" {
 		return s
@@ -92,4 +99,6 @@ func registerCommands(root *cobra.Command) {
 	rootCmd.AddCommand(schedulerCmd())
 	rootCmd.AddCommand(retryCmd())
 	rootCmd.AddCommand(startAllCmd())
+	rootCmd.AddCommand(secretCmd())
+	rootCmd.AddCommand(validateCmd())
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/persistence/client"
+	"github.com/spf13/cobra"
+)
+
+func secretCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage the secrets in the secret store",
+		Long:  `dagu secret [set|get|list|delete]`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cobra.CheckErr(config.LoadConfig())
+		},
+	}
+	cmd.AddCommand(secretSetCmd())
+	cmd.AddCommand(secretGetCmd())
+	cmd.AddCommand(secretListCmd())
+	cmd.AddCommand(secretDeleteCmd())
+	return cmd
+}
+
+func secretSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <name>",
+		Short: "Set the value of the secret",
+		Long:  `dagu secret set <name> (the value is read from stdin so that it is not left in the shell history)`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if f, ok := cmd.InOrStdin().(*os.File); ok {
+				if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+					fmt.Fprint(os.Stderr, "Enter the value of the secret: ")
+				}
+			}
+			line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if line == "" {
+				checkError(err)
+			}
+			value := strings.TrimRight(line, "\r\n")
+			ss := client.NewDataStoreFactory(config.Get()).NewSecretStore()
+			checkError(ss.Set(args[0], value))
+			log.Printf("Secret %s is set", args[0])
+		},
+	}
+}
+
+func secretGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <name>",
+		Short: "Display the value of the secret",
+		Long:  `dagu secret get <name>`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ss := client.NewDataStoreFactory(config.Get()).NewSecretStore()
+			value, err := ss.Get(args[0])
+			checkError(err)
+			fmt.Println(value)
+		},
+	}
+}
+
+func secretListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Display the names of the secrets",
+		Long:  `dagu secret list`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ss := client.NewDataStoreFactory(config.Get()).NewSecretStore()
+			names, err := ss.List()
+			checkError(err)
+			for _, name := range names {
+				fmt.Println(name)
+			}
+		},
+	}
+}
+
+func secretDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete the secret",
+		Long:  `dagu secret delete <name>`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ss := client.NewDataStoreFactory(config.Get()).NewSecretStore()
+			checkError(ss.Delete(args[0]))
+			log.Printf("Secret %s is deleted", args[0])
+		},
+	}
+}
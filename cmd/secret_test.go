@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSecretCommand(t *testing.T) {
+	tmpDir, _, _ := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	tests := []cmdTest{
+		{
+			args:        []string{"secret", "set", "TEST_TOKEN"},
+			stdin:       "s3cret\n",
+			expectedOut: []string{"Secret TEST_TOKEN is set"},
+		},
+		{
+			args:        []string{"secret", "get", "TEST_TOKEN"},
+			expectedOut: []string{"s3cret"},
+		},
+		{
+			args:        []string{"secret", "list"},
+			expectedOut: []string{"TEST_TOKEN"},
+		},
+		{
+			args:        []string{"start", testDAGFile("start_with_secret.yaml")},
+			expectedOut: []string{"token is *******"},
+		},
+		{
+			args:        []string{"secret", "delete", "TEST_TOKEN"},
+			expectedOut: []string{"Secret TEST_TOKEN is deleted"},
+		},
+	}
+
+	for _, tc := range tests {
+		if tc.args[0] == "start" {
+			testRunCommand(t, startCmd(), tc)
+			continue
+		}
+		testRunCommand(t, secretCmd(), tc)
+	}
+}
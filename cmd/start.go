@@ -11,7 +11,7 @@ func startCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "start [flags] <DAG file>",
 		Short: "Runs the DAG",
-		Long:  `dagu start [--params="param1 param2"] <DAG file>`,
+		Long:  `dagu start [--params="param1 param2"] [--req=<request-id>] <DAG file>`,
 		Args:  cobra.ExactArgs(1),
 		PreRun: func(cmd *cobra.Command, args []string) {
 			cobra.CheckErr(config.LoadConfig())
@@ -23,5 +23,6 @@ func startCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringP("params", "p", "", "parameters")
+	cmd.Flags().StringP("req", "r", "", "request-id of the run (generated if not set)")
 	return cmd
 }
@@ -24,6 +24,10 @@ func TestStartCommand(t *testing.T) {
 			args:        []string{"start", `--params="p3 p4"`, testDAGFile("start_with_params.yaml")},
 			expectedOut: []string{"params is p3 and p4"},
 		},
+		{
+			args:        []string{"start", `--params="COUNT=3"`, testDAGFile("start_with_typed_params.yaml")},
+			expectedOut: []string{"hello world 3"},
+		},
 	}
 
 	for _, tc := range tests {
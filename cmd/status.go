@@ -26,11 +26,16 @@ func statusCmd() *cobra.Command {
 			df := client.NewDataStoreFactory(config.Get())
 			e := engine.NewFactory(df, config.Get()).Create()
 
-			status, err := e.GetCurrentStatus(loadedDAG)
+			statuses, err := e.GetRunningStatuses(loadedDAG)
 			checkError(err)
+			if len(statuses) == 0 {
+				statuses = append(statuses, model.NewStatusDefault(loadedDAG))
+			}
 
-			res := &model.StatusResponse{Status: status}
-			log.Printf("Pid=%d Status=%s", res.Status.Pid, res.Status.Status)
+			for _, status := range statuses {
+				res := &model.StatusResponse{Status: status}
+				log.Printf("RequestId=%s Pid=%d Status=%s", res.Status.RequestId, res.Status.Pid, res.Status.Status)
+			}
 		},
 	}
 }
@@ -10,15 +10,18 @@ import (
 )
 
 func stopCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "stop <DAG file>",
+	cmd := &cobra.Command{
+		Use:   "stop [--req=<request-id>] <DAG file>",
 		Short: "Stop the running DAG",
-		Long:  `dagu stop <DAG file>`,
+		Long:  `dagu stop [--req=<request-id>] <DAG file>`,
 		Args:  cobra.ExactArgs(1),
 		PreRun: func(cmd *cobra.Command, args []string) {
 			cobra.CheckErr(config.LoadConfig())
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			reqID, err := cmd.Flags().GetString("req")
+			checkError(err)
+
 			loadedDAG, err := loadDAG(args[0], "")
 			checkError(err)
 
@@ -26,7 +29,9 @@ func stopCmd() *cobra.Command {
 
 			df := client.NewDataStoreFactory(config.Get())
 			e := engine.NewFactory(df, config.Get()).Create()
-			checkError(e.Stop(loadedDAG))
+			checkError(e.Stop(loadedDAG, reqID))
 		},
 	}
+	cmd.Flags().StringP("req", "r", "", "request-id of the run to stop (default: all runs)")
+	return cmd
 }
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/dag"
+	"github.com/spf13/cobra"
+)
+
+// validateResult is the result of the validate command in JSON.
+type validateResult struct {
+	Files    []validateFileResult `json:"files"`
+	Errors   int                  `json:"errors"`
+	Warnings int                  `json:"warnings"`
+}
+
+type validateFileResult struct {
+	File        string           `json:"file"`
+	Valid       bool             `json:"valid"`
+	Diagnostics []dag.Diagnostic `json:"diagnostics"`
+}
+
+func validateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [flags] <DAG file or directory>...",
+		Short: "Validate DAG files",
+		Long:  `dagu validate [--format=text|json] [--strict] <DAG file or directory>...`,
+		Args:  cobra.MinimumNArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			cobra.CheckErr(config.LoadConfig())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			format, err := cmd.Flags().GetString("format")
+			checkError(err)
+			strict, err := cmd.Flags().GetBool("strict")
+			checkError(err)
+
+			files, err := dagFiles(args)
+			checkError(err)
+
+			res := validateFiles(files)
+			switch format {
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				checkError(enc.Encode(res))
+			case "text":
+				for _, f := range res.Files {
+					for _, d := range f.Diagnostics {
+						fmt.Fprintln(cmd.OutOrStdout(), d)
+					}
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d file(s) checked: %d error(s), %d warning(s)\n",
+					len(res.Files), res.Errors, res.Warnings)
+			default:
+				checkError(fmt.Errorf("invalid format: %s", format))
+			}
+
+			if res.Errors > 0 || (strict && res.Warnings > 0) {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringP("format", "f", "text", "output format (text or json)")
+	cmd.Flags().Bool("strict", false, "exit with a non-zero status on warnings as well")
+	return cmd
+}
+
+// validateFiles lints the DAG files.
+func validateFiles(files []string) *validateResult {
+	res := &validateResult{Files: []validateFileResult{}}
+	for _, file := range files {
+		diags := dag.Lint(config.Get().BaseConfig, file)
+		f := validateFileResult{File: file, Valid: true, Diagnostics: []dag.Diagnostic{}}
+		for _, d := range diags {
+			if d.Severity == dag.SeverityError {
+				f.Valid = false
+				res.Errors++
+			} else {
+				res.Warnings++
+			}
+			f.Diagnostics = append(f.Diagnostics, d)
+		}
+		res.Files = append(res.Files, f)
+	}
+	return res
+}
+
+// dagFiles returns the DAG files in the arguments. The DAG files in a
+// directory and its subdirectories are returned for a directory, except
+// for the hidden directories and the base configuration files.
+func dagFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != arg && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if slices.Contains(dag.EXTENSIONS, filepath.Ext(path)) && !dag.IsBaseConfig(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
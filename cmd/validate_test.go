@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValidateCommand(t *testing.T) {
+	tmpDir, _, _ := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	tests := []cmdTest{
+		{
+			args:        []string{"validate", testDAGFile("start.yaml"), testDAGFile("status.yaml")},
+			expectedOut: []string{"2 file(s) checked: 0 error(s), 0 warning(s)"},
+		},
+		{
+			args:        []string{"validate", "--format", "json", testDAGFile("start.yaml")},
+			expectedOut: []string{`"valid": true`, `"errors": 0`},
+		},
+	}
+	for _, tc := range tests {
+		testRunCommand(t, validateCmd(), tc)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/dagu-dev/dagu/internal/logger"
 	"github.com/dagu-dev/dagu/internal/mailer"
 	"github.com/dagu-dev/dagu/internal/persistence/model"
+	"github.com/dagu-dev/dagu/internal/pool"
 	"github.com/dagu-dev/dagu/internal/reporter"
 	"github.com/dagu-dev/dagu/internal/scheduler"
 	"github.com/dagu-dev/dagu/internal/sock"
@@ -33,6 +34,7 @@ import (
 var (
 	errFailedStartSocketFrontend = errors.New("failed to start the socket frontend")
 	errDAGAlreadyRunning         = errors.New("the DAG is already running")
+	errDAGTimedOut               = errors.New("the DAG timed out")
 )
 
 // Agent is the interface to run / cancel / signal / status / etc.
@@ -50,6 +52,7 @@ type Agent struct {
 	socketServer     *sock.Server
 	requestId        string
 	finished         atomic.Bool
+	timedOut         atomic.Bool
 	lock             sync.RWMutex
 }
 
@@ -67,6 +70,12 @@ type Config struct {
 	DAGsDir string
 	Dry     bool
 
+	// RequestId is the request ID of the run. A new one is generated if it is empty.
+	RequestId string
+
+	// Pools are the resource pools that the steps acquire a slot of.
+	Pools pool.Pools
+
 	// RetryTarget is the status to retry.
 	RetryTarget *model.Status
 }
@@ -113,28 +122,31 @@ func (a *Agent) Status() *model.Status {
 	if scStatus == scheduler.StatusNone && a.graph.IsStarted() {
 		scStatus = scheduler.StatusRunning
 	}
+	secrets := a.DAG.SecretValues()
 	var ns []model.NodeStepPair
 	for _, n := range a.graph.Nodes() {
 		ns = append(ns, model.NodeStepPair{
-			Node: n.State(),
-			Step: n.Step(),
+			Node: scheduler.MaskState(n.State(), secrets),
+			Step: scheduler.MaskSecrets(n.Step(), secrets),
 		})
 	}
 	st, et := model.Time(a.graph.StartAt()), model.Time(a.graph.FinishAt())
 	status := model.NewStatus(a.DAG, ns, scStatus, os.Getpid(), st, et)
 	status.RequestId = a.requestId
 	status.Log = a.logManager.logFilename
+	status.TimedOut = a.timedOut.Load()
+	status.OutputVariables = scheduler.MaskOutputs(a.graph.OutputVariables(), secrets)
 	if node := a.scheduler.HandlerNode(constants.OnExit); node != nil {
-		status.OnExit = model.FromNode(node.State(), node.Step())
+		status.OnExit = model.FromNode(scheduler.MaskState(node.State(), secrets), scheduler.MaskSecrets(node.Step(), secrets))
 	}
 	if node := a.scheduler.HandlerNode(constants.OnSuccess); node != nil {
-		status.OnSuccess = model.FromNode(node.State(), node.Step())
+		status.OnSuccess = model.FromNode(scheduler.MaskState(node.State(), secrets), scheduler.MaskSecrets(node.Step(), secrets))
 	}
 	if node := a.scheduler.HandlerNode(constants.OnFailure); node != nil {
-		status.OnFailure = model.FromNode(node.State(), node.Step())
+		status.OnFailure = model.FromNode(scheduler.MaskState(node.State(), secrets), scheduler.MaskSecrets(node.Step(), secrets))
 	}
 	if node := a.scheduler.HandlerNode(constants.OnCancel); node != nil {
-		status.OnCancel = model.FromNode(node.State(), node.Step())
+		status.OnCancel = model.FromNode(scheduler.MaskState(node.State(), secrets), scheduler.MaskSecrets(node.Step(), secrets))
 	}
 	return status
 }
@@ -182,6 +194,17 @@ func (a *Agent) signal(sig os.Signal, allowOverride bool) {
 	}
 }
 
+// timeout stops the DAG in the same way as a stop request when it
+// exceeds the DAG timeout.
+func (a *Agent) timeout() {
+	if a.finished.Load() {
+		return
+	}
+	log.Printf("DAG timed out after %s. shutting down...", a.DAG.Timeout)
+	a.timedOut.Store(true)
+	a.signal(syscall.SIGTERM, true)
+}
+
 func (a *Agent) init() {
 	logDir := path.Join(a.DAG.LogDir, util.ValidFilename(a.DAG.Name, "_"))
 	config := &scheduler.Config{
@@ -190,6 +213,8 @@ func (a *Agent) init() {
 		Delay:         a.DAG.Delay,
 		Dry:           a.Dry,
 		RequestId:     a.requestId,
+		Secrets:       a.DAG.SecretValues(),
+		Pools:         a.Pools,
 	}
 
 	if a.DAG.HandlerOn.Exit != nil {
@@ -243,10 +268,19 @@ func (a *Agent) setupRetry() (err error) {
 		nodes = append(nodes, n.ToNode())
 	}
 	a.graph, err = scheduler.NewExecutionGraphForRetry(nodes...)
-	return
+	if err != nil {
+		return err
+	}
+	// the steps to be retried need the outputs of the steps that succeeded
+	a.graph.RestoreOutputVariables(a.RetryTarget.OutputVariables)
+	return nil
 }
 
 func (a *Agent) setupRequestId() error {
+	if a.Config.RequestId != "" {
+		a.requestId = a.Config.RequestId
+		return nil
+	}
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return err
@@ -268,7 +302,7 @@ func (a *Agent) setupDatabase() error {
 func (a *Agent) setupSocketServer() (err error) {
 	a.socketServer, err = sock.NewServer(
 		&sock.Config{
-			Addr:        a.DAG.SockAddr(),
+			Addr:        a.DAG.SockAddr(a.requestId),
 			HandlerFunc: a.HandleHTTP,
 		})
 	return
@@ -338,9 +372,17 @@ func (a *Agent) run(ctx context.Context) error {
 		util.LogErr("write status", a.historyStore.Write(a.Status()))
 	}()
 
+	if a.DAG.Timeout > 0 {
+		timer := time.AfterFunc(a.DAG.Timeout, a.timeout)
+		defer timer.Stop()
+	}
+
 	ctx = dag.NewContext(ctx, a.DAG, a.dataStoreFactory.NewDAGStore())
 
 	lastErr := a.scheduler.Schedule(ctx, a.graph, done)
+	if a.timedOut.Load() && lastErr == nil {
+		lastErr = fmt.Errorf("%w after %s", errDAGTimedOut, a.DAG.Timeout)
+	}
 	status := a.Status()
 
 	log.Println("schedule finished.")
@@ -381,13 +423,15 @@ func (a *Agent) dryRun() error {
 	return lastErr
 }
 
+// checkIsRunning returns an error if the DAG already has as many runs as
+// its maxConcurrentRuns, unless its overlap policy allows the runs to overlap.
 func (a *Agent) checkIsRunning() error {
-	status, err := a.engine.GetCurrentStatus(a.DAG)
+	statuses, err := a.engine.GetRunningStatuses(a.DAG)
 	if err != nil {
 		return err
 	}
-	if status.Status != scheduler.StatusNone {
-		return fmt.Errorf("%w. socket=%s", errDAGAlreadyRunning, a.DAG.SockAddr())
+	if len(statuses) >= a.DAG.RunLimit() {
+		return fmt.Errorf("%w. running=%d maxConcurrentRuns=%d", errDAGAlreadyRunning, len(statuses), a.DAG.MaxConcurrentRuns)
 	}
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -100,6 +101,48 @@ func TestCheckRunning(t *testing.T) {
 	require.Contains(t, err.Error(), "is already running")
 }
 
+func TestMaxConcurrentRuns(t *testing.T) {
+	tmpDir, e, df := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d := testLoadDAG(t, "max_concurrent_runs.yaml")
+	// Each run has its own history store as it does in its own process.
+	newDataStore := func() persistence.DataStoreFactory {
+		return client.NewDataStoreFactory(&config.Config{
+			DataDir: path.Join(tmpDir, ".dagu", "data"),
+		})
+	}
+	agents := []*agent.Agent{
+		agent.New(&agent.Config{DAG: d}, e, newDataStore()),
+		agent.New(&agent.Config{DAG: d}, e, newDataStore()),
+	}
+	var wg sync.WaitGroup
+	for _, a := range agents {
+		a := a
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = a.Run(context.Background())
+		}()
+		time.Sleep(time.Millisecond * 30)
+	}
+	// Wait for the runs to finish before the data directory is removed.
+	defer wg.Wait()
+
+	require.Eventually(t, func() bool {
+		statuses, err := e.GetRunningStatuses(d)
+		return err == nil && len(statuses) == 2
+	}, time.Second, time.Millisecond*50)
+	require.NotEqual(t, agents[0].Status().RequestId, agents[1].Status().RequestId)
+
+	a := agent.New(&agent.Config{DAG: d}, e, df)
+	err := a.Run(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is already running")
+}
+
 func TestDryRun(t *testing.T) {
 	tmpDir, e, df := setupTest(t)
 	defer func() {
@@ -143,6 +186,26 @@ func TestCancelDAG(t *testing.T) {
 	}
 }
 
+func TestTimeoutDAG(t *testing.T) {
+	tmpDir, e, df := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d := testLoadDAG(t, "timeout.yaml")
+	a := agent.New(&agent.Config{DAG: d}, e, df)
+
+	err := a.Run(context.Background())
+	require.Error(t, err)
+
+	status, err := e.GetLatestStatus(d)
+	require.NoError(t, err)
+	require.Equal(t, scheduler.StatusCancel, status.Status)
+	require.True(t, status.TimedOut)
+	require.Equal(t, scheduler.NodeStatusCancel, status.Nodes[0].Status)
+	require.Equal(t, scheduler.NodeStatusSuccess, status.OnCancel.Status)
+}
+
 func TestPreConditionInvalid(t *testing.T) {
 	tmpDir, e, df := setupTest(t)
 	defer func() {
@@ -261,6 +324,38 @@ func TestRetry(t *testing.T) {
 	}
 }
 
+func TestRetryOutputVariables(t *testing.T) {
+	tmpDir, e, df := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d := testLoadDAG(t, "retry_outputs.yaml")
+
+	a := agent.New(&agent.Config{DAG: d}, e, df)
+	err := a.Run(context.Background())
+	require.Error(t, err)
+
+	status := a.Status()
+	require.Equal(t, scheduler.StatusError, status.Status)
+	require.Equal(t, map[string]string{"RETRY_OUTPUT": "hello"}, status.OutputVariables)
+
+	// the output variables are restored from the status, not from the environment
+	require.NoError(t, os.Unsetenv("RETRY_OUTPUT"))
+	for _, n := range status.Nodes {
+		n.OutputVariables = nil
+	}
+	status.Nodes[1].Script = `test "$RETRY_OUTPUT" = "hello"`
+
+	a = agent.New(&agent.Config{DAG: d, RetryTarget: status}, e, df)
+	err = a.Run(context.Background())
+	require.NoError(t, err)
+
+	status = a.Status()
+	require.Equal(t, scheduler.StatusSuccess, status.Status)
+	require.Equal(t, scheduler.NodeStatusSuccess, status.Nodes[1].Status)
+}
+
 func TestHandleHTTP(t *testing.T) {
 	tmpDir, e, df := setupTest(t)
 	defer func() {
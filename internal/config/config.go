@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -23,6 +24,8 @@ type Config struct {
 	LogDir             string
 	DataDir            string
 	SuspendFlagsDir    string
+	SecretKey          string
+	SecretKeyFile      string
 	AdminLogsDir       string
 	BaseConfig         string
 	NavbarColor        string
@@ -32,13 +35,13 @@ type Config struct {
 	IsAuthToken        bool
 	AuthToken          string
 	LatestStatusToday  bool
-<<<<<<< HEAD
-=======
+	MaxRunningDAGs     int
+	Pools              map[string]int
+	CatchupWindow      time.Duration
 }
 
 func (cfg *Config) GetAPIBaseURL() string {
 	return "/api/v1"
->>>>>>> 6b68bb3079fad58e733c7d7d9b827db71b3946ae
 }
 
 type TLS struct {
@@ -95,6 +98,8 @@ func LoadConfig() error {
 	_ = viper.BindEnv("logDir", "DAGU_LOG_DIR")
 	_ = viper.BindEnv("dataDir", "DAGU_DATA_DIR")
 	_ = viper.BindEnv("suspendFlagsDir", "DAGU_SUSPEND_FLAGS_DIR")
+	_ = viper.BindEnv("secretKey", "DAGU_SECRET_KEY")
+	_ = viper.BindEnv("secretKeyFile", "DAGU_SECRET_KEY_FILE")
 	_ = viper.BindEnv("adminLogsDir", "DAGU_ADMIN_LOG_DIR")
 	_ = viper.BindEnv("navbarColor", "DAGU_NAVBAR_COLOR")
 	_ = viper.BindEnv("navbarTitle", "DAGU_NAVBAR_TITLE")
@@ -103,6 +108,8 @@ func LoadConfig() error {
 	_ = viper.BindEnv("isAuthToken", "DAGU_IS_AUTHTOKEN")
 	_ = viper.BindEnv("authToken", "DAGU_AUTHTOKEN")
 	_ = viper.BindEnv("latestStatusToday", "DAGU_LATEST_STATUS")
+	_ = viper.BindEnv("maxRunningDAGs", "DAGU_MAX_RUNNING_DAGS")
+	_ = viper.BindEnv("catchupWindow", "DAGU_CATCHUP_WINDOW")
 
 	executable, err := os.Executable()
 	if err != nil {
@@ -122,12 +129,16 @@ func LoadConfig() error {
 	viper.SetDefault("logDir", path.Join(appHome, "logs"))
 	viper.SetDefault("dataDir", path.Join(appHome, "data"))
 	viper.SetDefault("suspendFlagsDir", path.Join(appHome, "suspend"))
+	viper.SetDefault("secretKey", "")
+	viper.SetDefault("secretKeyFile", path.Join(appHome, "secret.key"))
 	viper.SetDefault("adminLogsDir", path.Join(appHome, "logs", "admin"))
 	viper.SetDefault("navbarColor", "")
 	viper.SetDefault("navbarTitle", "Dagu")
 	viper.SetDefault("isAuthToken", "0")
 	viper.SetDefault("authToken", "0")
 	viper.SetDefault("latestStatusToday", "0")
+	viper.SetDefault("maxRunningDAGs", "0")
+	viper.SetDefault("catchupWindow", "24h")
 
 	viper.AutomaticEnv()
 
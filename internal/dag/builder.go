@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -34,6 +35,13 @@ type buildOpts struct {
 	// This is useful when loading details for a DAG, but not
 	// for execution.
 	noEval bool
+	// resolveSecrets specifies whether to resolve the values of the secrets
+	// and set them as environment variables. It is set only when loading
+	// the DAG to run it.
+	resolveSecrets bool
+	// secretStore is the store to read the secrets that refer to the
+	// secret store from when resolving the secrets.
+	secretStore SecretStore
 }
 
 // builder is used to build a DAG from a configuration definition.
@@ -70,14 +78,30 @@ var (
 	errExecutorConfigValueMustBeMap       = errors.New("executor.config value must be a map")
 	errExecutorHasInvalidKey              = errors.New("executor has invalid key")
 	errExecutorConfigMustBeStringOrMap    = errors.New("executor config must be string or map")
+	errTimeoutSecMustBeNonNegative        = errors.New("timeoutSec must be greater than or equal to 0")
+	errRetryBackoffMustBeAtLeastOne       = errors.New("retryPolicy.backoff must be greater than or equal to 1")
+	errRetryIntervalMustBeNonNegative     = errors.New("retryPolicy intervals must be greater than or equal to 0")
+	errRepeatLimitMustBeNonNegative       = errors.New("repeatPolicy.limit must be greater than or equal to 0")
+	errRepeatExpectedRequiresCondition    = errors.New("repeatPolicy.expected requires repeatPolicy.condition")
+	errInvalidTriggerRule                 = errors.New("invalid triggerRule")
+	errWaitForRequiresPreconditions       = errors.New("waitFor requires preconditions")
+	errWaitForMustBeNonNegative           = errors.New("waitFor values must be greater than or equal to 0")
+	errMaxConcurrentRunsMustBePositive    = errors.New("maxConcurrentRuns must be greater than or equal to 1")
+	errInvalidOverlapPolicy               = errors.New("invalid overlapPolicy")
 )
 
 // builderFunc is a function that builds a part of the DAG.
 type builderFunc func() error
 
 // callBuilderFunc calls a builder function and adds any errors to the error list.
-func (b *builder) callBuilderFunc(fn builderFunc) {
+// The errors are marked with the path to the field that the function builds
+// unless they already have a more specific one.
+func (b *builder) callBuilderFunc(path string, fn builderFunc) {
 	if err := fn(); err != nil {
+		var fe *fieldError
+		if path != "" && !errors.As(err, &fe) {
+			err = &fieldError{path: path, err: err}
+		}
 		b.errs.Add(err)
 	}
 }
@@ -85,6 +109,8 @@ func (b *builder) callBuilderFunc(fn builderFunc) {
 var (
 	defaultHistoryRetentionDays = 30
 	defaultMaxCleanUpTime       = time.Second * 60
+	defaultMaxConcurrentRuns    = 1
+	defaultWaitForPollInterval  = time.Second * 5
 )
 
 // build builds a DAG from a configuration definition and the base DAG.
@@ -102,28 +128,33 @@ func (b *builder) build(def *definition, envs []string) (*DAG, error) {
 		Delay:       time.Second * time.Duration(def.DelaySec),
 		RestartWait: time.Second * time.Duration(def.RestartWaitSec),
 		Tags:        parseTags(def.Tags),
+		Priority:    def.Priority,
+		Catchup:     def.Catchup,
 	}
 	b.stepBuilder = stepBuilder{noEval: b.opts.noEval}
 
-	b.callBuilderFunc(b.buildEnvs)
-	b.callBuilderFunc(b.buildSchedule)
-	b.callBuilderFunc(b.buildMailOnConfig)
-	b.callBuilderFunc(b.buildParams)
+	b.callBuilderFunc("secrets", b.buildSecrets)
+	b.callBuilderFunc("env", b.buildEnvs)
+	b.callBuilderFunc("schedule", b.buildSchedule)
+	b.callBuilderFunc("mailOn", b.buildMailOnConfig)
+	b.callBuilderFunc("params", b.buildParams)
+	b.callBuilderFunc("maxConcurrentRuns", b.buildMaxConcurrentRuns)
+	b.callBuilderFunc("overlapPolicy", b.buildOverlapPolicy)
 
 	// If metadataOnly is set, return the DAG with the metadata.
 	// This is done for avoiding unnecessary processing when
 	// only the metadata is required.
 	if !b.opts.metadataOnly {
-		b.callBuilderFunc(b.buildSteps)
-		b.callBuilderFunc(b.buildLogDir)
-		b.callBuilderFunc(b.buildHandlers)
-		b.callBuilderFunc(b.buildSMTPConfig)
-		b.callBuilderFunc(b.buildErrMailConfig)
-		b.callBuilderFunc(b.buildInfoMailConfig)
-		b.callBuilderFunc(b.buildMiscs)
+		b.callBuilderFunc("steps", b.buildSteps)
+		b.callBuilderFunc("logDir", b.buildLogDir)
+		b.callBuilderFunc("handlerOn", b.buildHandlers)
+		b.callBuilderFunc("smtp", b.buildSMTPConfig)
+		b.callBuilderFunc("errorMail", b.buildErrMailConfig)
+		b.callBuilderFunc("infoMail", b.buildInfoMailConfig)
+		b.callBuilderFunc("", b.buildMiscs)
 
 		if err := assertFunctions(def.Functions); err != nil {
-			b.errs.Add(err)
+			b.errs.Add(&fieldError{path: "functions", err: err})
 		}
 	}
 
@@ -245,6 +276,13 @@ func (b *builder) buildEnvs() error {
 	return nil
 }
 
+// buildSecrets builds the secrets for the DAG.
+// The values are resolved only when the DAG is loaded to run it.
+func (b *builder) buildSecrets() (err error) {
+	b.dag.Secrets, err = buildSecrets(b.def.Secrets, b.def.secretRefs, b.opts.resolveSecrets, b.opts.secretStore)
+	return err
+}
+
 // buildLogDir builds the log directory for the DAG.
 func (b *builder) buildLogDir() (err error) {
 	b.dag.LogDir, err = evaluateValue(b.def.LogDir)
@@ -252,16 +290,35 @@ func (b *builder) buildLogDir() (err error) {
 }
 
 // buildParams builds the parameters for the DAG.
+// The params are either a free-form string or a schema of typed parameters.
+// When the schema is defined, the given parameters are validated against it.
 func (b *builder) buildParams() (err error) {
-	b.dag.DefaultParams = b.def.Params
+	switch v := b.def.Params.(type) {
+	case nil:
+	case string:
+		b.dag.DefaultParams = v
+	case []any:
+		if b.dag.ParamSchema, err = buildParamSchema(v); err != nil {
+			return err
+		}
+		b.dag.DefaultParams = defaultParams(b.dag.ParamSchema)
+	default:
+		return errParamsMustBeStringOrArray
+	}
 
 	params := b.dag.DefaultParams
 	if b.opts.parameters != "" {
 		params = b.opts.parameters
 	}
 
+	if b.dag.ParamSchema != nil && !b.opts.noEval {
+		if params, err = resolveParams(b.dag.ParamSchema, b.opts.parameters); err != nil {
+			return err
+		}
+	}
+
 	var envs []string
-	b.dag.Params, envs, err = processParams(params, !b.opts.noEval, b.opts)
+	b.dag.Params, envs, err = processParams(params, !b.opts.noEval, b.opts, b.dag.ParamSchema)
 	if err == nil {
 		b.dag.Env = append(b.dag.Env, envs...)
 	}
@@ -272,37 +329,66 @@ func (b *builder) buildParams() (err error) {
 // buildHandlers builds the handlers for the DAG.
 // The handlers are executed when the DAG is stopped, succeeded, failed, or cancelled.
 func (b *builder) buildHandlers() (err error) {
+	variables := excludeSecrets(b.dag.Env, b.dag.Secrets)
+
 	if b.def.HandlerOn.Exit != nil {
 		b.def.HandlerOn.Exit.Name = constants.OnExit
-		if b.dag.HandlerOn.Exit, err = b.stepBuilder.buildStep(b.dag.Env, b.def.HandlerOn.Exit, b.def.Functions); err != nil {
-			return err
+		if b.dag.HandlerOn.Exit, err = b.stepBuilder.buildStep(variables, b.def.HandlerOn.Exit, b.def.Functions); err != nil {
+			return &fieldError{path: "handlerOn.exit", err: err}
 		}
 	}
 
 	if b.def.HandlerOn.Success != nil {
 		b.def.HandlerOn.Success.Name = constants.OnSuccess
-		if b.dag.HandlerOn.Success, err = b.stepBuilder.buildStep(b.dag.Env, b.def.HandlerOn.Success, b.def.Functions); err != nil {
-			return
+		if b.dag.HandlerOn.Success, err = b.stepBuilder.buildStep(variables, b.def.HandlerOn.Success, b.def.Functions); err != nil {
+			return &fieldError{path: "handlerOn.success", err: err}
 		}
 	}
 
 	if b.def.HandlerOn.Failure != nil {
 		b.def.HandlerOn.Failure.Name = constants.OnFailure
-		if b.dag.HandlerOn.Failure, err = b.stepBuilder.buildStep(b.dag.Env, b.def.HandlerOn.Failure, b.def.Functions); err != nil {
-			return
+		if b.dag.HandlerOn.Failure, err = b.stepBuilder.buildStep(variables, b.def.HandlerOn.Failure, b.def.Functions); err != nil {
+			return &fieldError{path: "handlerOn.failure", err: err}
 		}
 	}
 
 	if b.def.HandlerOn.Cancel != nil {
 		b.def.HandlerOn.Cancel.Name = constants.OnCancel
-		if b.dag.HandlerOn.Cancel, err = b.stepBuilder.buildStep(b.dag.Env, b.def.HandlerOn.Cancel, b.def.Functions); err != nil {
-			return
+		if b.dag.HandlerOn.Cancel, err = b.stepBuilder.buildStep(variables, b.def.HandlerOn.Cancel, b.def.Functions); err != nil {
+			return &fieldError{path: "handlerOn.cancel", err: err}
 		}
 	}
 
 	return nil
 }
 
+// buildMaxConcurrentRuns builds the maximum number of concurrent runs of the DAG.
+// It is a part of the metadata because the scheduler needs it to decide
+// whether a scheduled run can start.
+func (b *builder) buildMaxConcurrentRuns() error {
+	if b.def.MaxConcurrentRuns < 0 {
+		return fmt.Errorf("%w: %d", errMaxConcurrentRunsMustBePositive, b.def.MaxConcurrentRuns)
+	}
+	b.dag.MaxConcurrentRuns = b.def.MaxConcurrentRuns
+	if b.dag.MaxConcurrentRuns == 0 {
+		b.dag.MaxConcurrentRuns = defaultMaxConcurrentRuns
+	}
+	return nil
+}
+
+// buildOverlapPolicy builds the overlap policy of the scheduled runs.
+// It is a part of the metadata for the same reason as maxConcurrentRuns.
+func (b *builder) buildOverlapPolicy() error {
+	b.dag.OverlapPolicy = OverlapPolicy(b.def.OverlapPolicy)
+	if !b.dag.OverlapPolicy.IsValid() {
+		return fmt.Errorf("%w: %s", errInvalidOverlapPolicy, b.def.OverlapPolicy)
+	}
+	if b.dag.OverlapPolicy == "" {
+		b.dag.OverlapPolicy = OverlapPolicySkip
+	}
+	return nil
+}
+
 // buildMiscs builds the miscellaneous fields for the DAG.
 func (b *builder) buildMiscs() (err error) {
 	if b.def.HistRetentionDays != nil {
@@ -310,12 +396,20 @@ func (b *builder) buildMiscs() (err error) {
 	}
 
 	b.dag.Preconditions = buildConditions(b.def.Preconditions)
+	if err := assertConditions(b.dag.Preconditions); err != nil {
+		return err
+	}
 	b.dag.MaxActiveRuns = b.def.MaxActiveRuns
 
 	if b.def.MaxCleanUpTimeSec != nil {
 		b.dag.MaxCleanUpTime = time.Second * time.Duration(*b.def.MaxCleanUpTimeSec)
 	}
 
+	if b.def.TimeoutSec < 0 {
+		return fmt.Errorf("%w: %d", errTimeoutSecMustBeNonNegative, b.def.TimeoutSec)
+	}
+	b.dag.Timeout = time.Second * time.Duration(b.def.TimeoutSec)
+
 	return nil
 }
 
@@ -382,7 +476,9 @@ func stringifyParam(param paramPair) string {
 }
 
 // processParams parses and processes the parameters for the DAG.
-func processParams(value string, eval bool, options buildOpts) (
+// The values of the parameters defined in the schema are validated
+// after they are evaluated.
+func processParams(value string, eval bool, options buildOpts, schema []Param) (
 	params []string,
 	envs []string,
 	err error,
@@ -400,6 +496,14 @@ func processParams(value string, eval bool, options buildOpts) (
 			p.value = os.ExpandEnv(p.value)
 		}
 
+		if eval && p.name != "" {
+			if i := slices.IndexFunc(schema, func(s Param) bool { return s.Name == p.name }); i >= 0 {
+				if err = schema[i].validate(p.value); err != nil {
+					return
+				}
+			}
+		}
+
 		strParam := stringifyParam(p)
 		ret = append(ret, strParam)
 
@@ -504,17 +608,15 @@ func loadVariables(strVariables any, opts buildOpts) (
 
 // buildSteps builds the steps for the DAG.
 func (b *builder) buildSteps() error {
-	var ret []Step
-
-	for _, stepDef := range b.def.Steps {
-		step, err := b.stepBuilder.buildStep(b.dag.Env, stepDef, b.def.Functions)
-		if err != nil {
-			return err
-		}
-		ret = append(ret, *step)
+	// The secrets are set as environment variables of the process,
+	// so they are excluded from the variables stored in the status.
+	variables := excludeSecrets(b.dag.Env, b.dag.Secrets)
+	steps, err := b.stepBuilder.buildSteps(variables, b.def.Steps, b.def.Functions)
+	if err != nil {
+		return err
 	}
 
-	b.dag.Steps = ret
+	b.dag.Steps = steps
 
 	return nil
 }
@@ -563,6 +665,65 @@ var (
 	}
 )
 
+// buildSteps builds the steps from the step definitions.
+// Steps with a static matrix are expanded and the dependencies on them
+// are rewritten to the expanded steps.
+func (b *stepBuilder) buildSteps(variables []string, defs []*stepDef, fns []*funcDef) ([]Step, error) {
+	var (
+		ret  []Step
+		errs errorList
+	)
+
+	// expanded maps the name of a step with a matrix to the names of
+	// the expanded steps so that the dependencies can be rewritten.
+	expanded := map[string][]string{}
+
+	// All the steps are built to report the errors of every step.
+	for i, stepDef := range defs {
+		step, err := b.buildStep(variables, stepDef, fns)
+		if err != nil {
+			errs.Add(&fieldError{path: fmt.Sprintf("steps[%d]", i), err: err})
+			continue
+		}
+		if len(stepDef.Matrix) == 0 {
+			ret = append(ret, *step)
+			continue
+		}
+		vars, err := buildMatrix(stepDef.Matrix)
+		if err != nil {
+			errs.Add(&fieldError{path: fmt.Sprintf("steps[%d].matrix", i), err: err})
+			continue
+		}
+		if isDynamicMatrix(vars) {
+			// the step is expanded at runtime
+			step.Matrix = vars
+			ret = append(ret, *step)
+			continue
+		}
+		for _, s := range ExpandMatrix(*step, vars) {
+			expanded[step.Name] = append(expanded[step.Name], s.Name)
+			ret = append(ret, s)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &errs
+	}
+
+	for i := 0; len(expanded) > 0 && i < len(ret); i++ {
+		var depends []string
+		for _, dep := range ret[i].Depends {
+			if names, ok := expanded[dep]; ok {
+				depends = append(depends, names...)
+				continue
+			}
+			depends = append(depends, dep)
+		}
+		ret[i].Depends = depends
+	}
+
+	return ret, nil
+}
+
 // buildStep builds a step from the step definition.
 // nolint // cognitive complexity
 func (b *stepBuilder) buildStep(variables []string, def *stepDef, fns []*funcDef) (*Step, error) {
@@ -582,6 +743,8 @@ func (b *stepBuilder) buildStep(variables []string, def *stepDef, fns []*funcDef
 		Depends:        def.Depends,
 		MailOnError:    def.MailOnError,
 		Preconditions:  buildConditions(def.Preconditions),
+		GenerateSteps:  def.GenerateSteps,
+		Pool:           def.Pool,
 		ExecutorConfig: ExecutorConfig{Config: make(map[string]any)},
 	}
 
@@ -826,21 +989,33 @@ func parseFuncCall(step *Step, call *callFuncDef, funcs []*funcDef) error {
 
 // parseMiscs parses the miscellaneous fields in the step definition.
 func parseMiscs(def *stepDef, step *Step) error {
+	if err := assertConditions(step.Preconditions); err != nil {
+		return err
+	}
+
 	if def.ContinueOn != nil {
 		step.ContinueOn.Skipped = def.ContinueOn.Skipped
 		step.ContinueOn.Failure = def.ContinueOn.Failure
 	}
 
 	if def.RetryPolicy != nil {
+		if err := assertRetryPolicy(def.RetryPolicy); err != nil {
+			return err
+		}
 		step.RetryPolicy = &RetryPolicy{
-			Limit:    def.RetryPolicy.Limit,
-			Interval: time.Second * time.Duration(def.RetryPolicy.IntervalSec),
+			Limit:       def.RetryPolicy.Limit,
+			Interval:    time.Second * time.Duration(def.RetryPolicy.IntervalSec),
+			Backoff:     def.RetryPolicy.Backoff,
+			MaxInterval: time.Second * time.Duration(def.RetryPolicy.MaxIntervalSec),
+			Jitter:      time.Second * time.Duration(def.RetryPolicy.JitterSec),
+			ExitCodes:   def.RetryPolicy.ExitCodes,
 		}
 	}
 
 	if def.RepeatPolicy != nil {
-		step.RepeatPolicy.Repeat = def.RepeatPolicy.Repeat
-		step.RepeatPolicy.Interval = time.Second * time.Duration(def.RepeatPolicy.IntervalSec)
+		if err := buildRepeatPolicy(def.RepeatPolicy, &step.RepeatPolicy); err != nil {
+			return err
+		}
 	}
 
 	if def.SignalOnStop != nil {
@@ -852,16 +1027,39 @@ func parseMiscs(def *stepDef, step *Step) error {
 		step.SignalOnStop = sigDef
 	}
 
+	if def.TimeoutSec < 0 {
+		return fmt.Errorf("%w: %d", errTimeoutSecMustBeNonNegative, def.TimeoutSec)
+	}
+	step.Timeout = time.Second * time.Duration(def.TimeoutSec)
+
+	if def.WaitFor != nil {
+		if err := buildWaitFor(def.WaitFor, step); err != nil {
+			return err
+		}
+	}
+
+	step.TriggerRule = TriggerRule(def.TriggerRule)
+	if !step.TriggerRule.IsValid() {
+		return fmt.Errorf("%w: %s", errInvalidTriggerRule, def.TriggerRule)
+	}
+
 	return nil
 }
 
 // expandEnv expands the environment variables in the value if the noEval option is false.
+// The references to the outputs of other steps such as ${steps.a.outputs.b} are
+// kept as they are so that they are expanded when the step runs.
 func expandEnv(val string, noEval bool) string {
 	if noEval {
 		return val
 	}
 
-	return os.ExpandEnv(val)
+	return os.Expand(val, func(name string) string {
+		if strings.HasPrefix(name, "steps.") && strings.Contains(name, ".outputs.") {
+			return "${" + name + "}"
+		}
+		return os.Getenv(name)
+	})
 }
 
 // parseKey parses the key as a string.
@@ -944,6 +1142,17 @@ func buildConditions(cond []*conditionDef) []*Condition {
 	return ret
 }
 
+// assertConditions checks that the expected values of the conditions
+// are valid expressions.
+func assertConditions(conds []*Condition) error {
+	for _, c := range conds {
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // parseTags builds a list of tags from the value.
 // It converts the tags to lowercase and trims the whitespace.
 func parseTags(value string) []string {
@@ -1021,6 +1230,66 @@ func assertFunctions(fns []*funcDef) error {
 	return nil
 }
 
+// buildWaitFor builds the sensor mode of the preconditions.
+func buildWaitFor(def *waitForDef, step *Step) error {
+	if len(step.Preconditions) == 0 {
+		return errWaitForRequiresPreconditions
+	}
+	if def.PollIntervalSec < 0 || def.TimeoutSec < 0 {
+		return fmt.Errorf("%w: pollIntervalSec=%d timeoutSec=%d",
+			errWaitForMustBeNonNegative, def.PollIntervalSec, def.TimeoutSec)
+	}
+	step.WaitFor = &WaitFor{
+		PollInterval: time.Second * time.Duration(def.PollIntervalSec),
+		Timeout:      time.Second * time.Duration(def.TimeoutSec),
+	}
+	if step.WaitFor.PollInterval == 0 {
+		step.WaitFor.PollInterval = defaultWaitForPollInterval
+	}
+	return nil
+}
+
+// buildRepeatPolicy builds the repeat policy from the definition.
+// Specifying a condition, exit codes or a limit implies `repeat: true`.
+func buildRepeatPolicy(def *repeatPolicyDef, policy *RepeatPolicy) error {
+	if def.Limit < 0 {
+		return fmt.Errorf("%w: %d", errRepeatLimitMustBeNonNegative, def.Limit)
+	}
+	if def.Condition == "" && def.Expected != "" {
+		return errRepeatExpectedRequiresCondition
+	}
+
+	policy.Repeat = def.Repeat
+	policy.Interval = time.Second * time.Duration(def.IntervalSec)
+	policy.ExitCode = def.ExitCode
+	policy.Limit = def.Limit
+	if def.Condition != "" {
+		policy.Condition = &Condition{
+			Condition: def.Condition,
+			Expected:  def.Expected,
+		}
+		if err := policy.Condition.validate(); err != nil {
+			return err
+		}
+	}
+	if policy.IsConditional() || policy.Limit > 0 {
+		policy.Repeat = true
+	}
+
+	return nil
+}
+
+// assertRetryPolicy validates the retry policy definition.
+func assertRetryPolicy(def *retryPolicyDef) error {
+	if def.Backoff != 0 && def.Backoff < 1 {
+		return fmt.Errorf("%w: %v", errRetryBackoffMustBeAtLeastOne, def.Backoff)
+	}
+	if def.IntervalSec < 0 || def.MaxIntervalSec < 0 || def.JitterSec < 0 {
+		return errRetryIntervalMustBeNonNegative
+	}
+	return nil
+}
+
 // assertStepDef validates the step definition.
 func assertStepDef(def *stepDef, funcs []*funcDef) error {
 	// Step name is required.
@@ -6,6 +6,7 @@ import (
 	"path"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/dagu-dev/dagu/internal/config"
 	"github.com/stretchr/testify/require"
@@ -261,6 +262,11 @@ func Test_expandEnv(t *testing.T) {
 		require.Equal(t, expandEnv("${FOO}", false), "BAR")
 		require.Equal(t, expandEnv("${FOO}", true), "${FOO}")
 	})
+	t.Run("keep output refs", func(t *testing.T) {
+		_ = os.Setenv("FOO", "BAR")
+		require.Equal(t, "BAR/${steps.a.outputs.dir}", expandEnv("${FOO}/${steps.a.outputs.dir}", false))
+		require.Equal(t, "${steps.a.outputs.result.items[0]}", expandEnv("${steps.a.outputs.result.items[0]}", false))
+	})
 }
 
 func TestBuilder_BuildTags(t *testing.T) {
@@ -529,6 +535,492 @@ steps:
 	}
 }
 
+func TestBuilder_BuildRetryPolicy(t *testing.T) {
+	t.Run("backoff and exit codes", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    retryPolicy:
+      limit: 3
+      intervalSec: 5
+      backoff: 2
+      maxIntervalSec: 60
+      jitterSec: 3
+      exitCodes: [75, 76]
+`))
+		require.NoError(t, err)
+		require.Equal(t, &RetryPolicy{
+			Limit:       3,
+			Interval:    time.Second * 5,
+			Backoff:     2,
+			MaxInterval: time.Minute,
+			Jitter:      time.Second * 3,
+			ExitCodes:   []int{75, 76},
+		}, ret.Steps[0].RetryPolicy)
+	})
+	t.Run("[Invalid] backoff less than 1", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    retryPolicy:
+      limit: 3
+      backoff: 0.5
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildRepeatPolicy(t *testing.T) {
+	t.Run("condition and limit", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    repeatPolicy:
+      condition: "` + "`echo ok`" + `"
+      expected: "ok"
+      exitCode: [75]
+      intervalSec: 10
+      limit: 5
+`))
+		require.NoError(t, err)
+		require.Equal(t, RepeatPolicy{
+			Repeat:    true,
+			Interval:  time.Second * 10,
+			Condition: &Condition{Condition: "`echo ok`", Expected: "ok"},
+			ExitCode:  []int{75},
+			Limit:     5,
+		}, ret.Steps[0].RepeatPolicy)
+	})
+	t.Run("[Invalid] negative limit", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    repeatPolicy:
+      limit: -1
+`))
+		require.Error(t, err)
+	})
+	t.Run("[Invalid] expected without condition", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    repeatPolicy:
+      expected: "ok"
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildTriggerRule(t *testing.T) {
+	t.Run("triggerRule", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+  - name: "2"
+    command: "true"
+    depends: ["1"]
+    triggerRule: all_done
+`))
+		require.NoError(t, err)
+		require.Equal(t, TriggerRule(""), ret.Steps[0].TriggerRule)
+		require.Equal(t, TriggerRuleAllDone, ret.Steps[1].TriggerRule)
+	})
+	t.Run("[Invalid] unknown triggerRule", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    triggerRule: sometimes
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildPreconditions(t *testing.T) {
+	t.Run("expression", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+preconditions:
+  - condition: "$TEST_VALUE"
+    expected: "expr:re:^[0-9]+$"
+steps:
+  - name: "1"
+    command: "true"
+    preconditions:
+      - condition: "$TEST_VALUE"
+        expected: "expr:>= 10 and < 20"
+`))
+		require.NoError(t, err)
+		require.Equal(t, "expr:re:^[0-9]+$", ret.Preconditions[0].Expected)
+		require.Equal(t, "expr:>= 10 and < 20", ret.Steps[0].Preconditions[0].Expected)
+	})
+	t.Run("[Invalid] regular expression", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+preconditions:
+  - condition: "$TEST_VALUE"
+    expected: "expr:re:["
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.Error(t, err)
+	})
+	t.Run("[Invalid] comparison with a non-number", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    preconditions:
+      - condition: "$TEST_VALUE"
+        expected: "expr:> abc"
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildWaitFor(t *testing.T) {
+	t.Run("waitFor", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    preconditions:
+      - condition: "` + "`ls /tmp/flag`" + `"
+        expected: "/tmp/flag"
+    waitFor:
+      pollIntervalSec: 30
+      timeoutSec: 3600
+`))
+		require.NoError(t, err)
+		require.Equal(t, &WaitFor{
+			PollInterval: time.Second * 30,
+			Timeout:      time.Hour,
+		}, ret.Steps[0].WaitFor)
+	})
+	t.Run("default poll interval", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    preconditions:
+      - condition: "$FLAG"
+        expected: "1"
+    waitFor: {}
+`))
+		require.NoError(t, err)
+		require.Equal(t, defaultWaitForPollInterval, ret.Steps[0].WaitFor.PollInterval)
+		require.Zero(t, ret.Steps[0].WaitFor.Timeout)
+	})
+	t.Run("[Invalid] without preconditions", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    waitFor:
+      pollIntervalSec: 30
+`))
+		require.Error(t, err)
+	})
+	t.Run("[Invalid] negative timeoutSec", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    preconditions:
+      - condition: "$FLAG"
+        expected: "1"
+    waitFor:
+      timeoutSec: -1
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildMaxConcurrentRuns(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.NoError(t, err)
+		require.Equal(t, 1, ret.MaxConcurrentRuns)
+	})
+	t.Run("maxConcurrentRuns", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+maxConcurrentRuns: 3
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.NoError(t, err)
+		require.Equal(t, 3, ret.MaxConcurrentRuns)
+	})
+	t.Run("[Invalid] negative maxConcurrentRuns", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+maxConcurrentRuns: -1
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildPriority(t *testing.T) {
+	ret, err := LoadMetadata(path.Join(testdataDir, "default.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, 0, ret.Priority)
+
+	ret, err = LoadYAML([]byte(`
+priority: 10
+steps:
+  - name: "1"
+    command: "true"
+`))
+	require.NoError(t, err)
+	require.Equal(t, 10, ret.Priority)
+}
+
+func TestBuilder_BuildOverlapPolicy(t *testing.T) {
+	ret, err := LoadMetadata(path.Join(testdataDir, "default.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, OverlapPolicySkip, ret.OverlapPolicy)
+	require.Equal(t, 1, ret.RunLimit())
+
+	ret, err = LoadYAML([]byte(`
+overlapPolicy: allow
+steps:
+  - name: "1"
+    command: "true"
+`))
+	require.NoError(t, err)
+	require.Equal(t, OverlapPolicyAllow, ret.OverlapPolicy)
+	require.Equal(t, 1, ret.RunLimit())
+
+	_, err = LoadYAML([]byte(`
+overlapPolicy: sometimes
+steps:
+  - name: "1"
+    command: "true"
+`))
+	require.ErrorIs(t, err, errInvalidOverlapPolicy)
+}
+
+func TestBuilder_BuildCatchup(t *testing.T) {
+	ret, err := LoadMetadata(path.Join(testdataDir, "default.yaml"))
+	require.NoError(t, err)
+	require.False(t, ret.Catchup)
+
+	ret, err = LoadYAML([]byte(`
+catchup: true
+steps:
+  - name: "1"
+    command: "true"
+`))
+	require.NoError(t, err)
+	require.True(t, ret.Catchup)
+}
+
+func TestBuilder_BuildParamSchema(t *testing.T) {
+	t.Run("schema", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+params:
+  - name: ENV
+    type: enum
+    values: [dev, prod]
+    default: dev
+    description: target environment
+  - name: COUNT
+    type: int
+    default: 3
+  - name: DATE
+    type: date
+    required: true
+  - name: NAME
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.NoError(t, err)
+		require.Equal(t, []Param{
+			{Name: "ENV", Type: ParamTypeEnum, Default: "dev", Description: "target environment", Values: []string{"dev", "prod"}},
+			{Name: "COUNT", Type: ParamTypeInt, Default: "3"},
+			{Name: "DATE", Type: ParamTypeDate, Required: true},
+			{Name: "NAME", Type: ParamTypeString},
+		}, ret.ParamSchema)
+		require.Equal(t, `ENV="dev" COUNT="3"`, ret.DefaultParams)
+	})
+	t.Run("[Invalid] schema", func(t *testing.T) {
+		tests := map[string]string{
+			"unknown type":        "  - name: X\n    type: float",
+			"missing name":        "  - type: int",
+			"duplicate name":      "  - name: X\n  - name: X",
+			"enum without values": "  - name: X\n    type: enum",
+			"invalid default":     "  - name: X\n    type: int\n    default: abc",
+			"invalid enum value":  "  - name: X\n    type: enum\n    values: [a]\n    default: b",
+			"unknown key":         "  - name: X\n    invalid: true",
+		}
+		for name, params := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := LoadYAML([]byte("params:\n" + params + "\nsteps:\n  - name: \"1\"\n    command: \"true\"\n"))
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestBuilder_BuildSecrets(t *testing.T) {
+	t.Run("without eval", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+secrets:
+  - name: DB_PASSWORD
+    env: PROD_DB_PASSWORD
+  - name: API_TOKEN
+    file: /run/secrets/token
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.NoError(t, err)
+		require.Equal(t, []Secret{
+			{Name: "DB_PASSWORD", Env: "PROD_DB_PASSWORD"},
+			{Name: "API_TOKEN", File: "/run/secrets/token"},
+		}, ret.Secrets)
+		require.Empty(t, ret.SecretValues())
+	})
+	t.Run("store reference", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+secrets:
+  - name: TOKEN
+    store: TOKEN
+steps:
+  - name: "1"
+    command: echo ${secret:PASSWORD} ${secret:TOKEN}
+`))
+		require.NoError(t, err)
+		require.Equal(t, []Secret{
+			{Name: "TOKEN", Store: "TOKEN"},
+			{Name: "PASSWORD", Store: "PASSWORD"},
+		}, ret.Secrets)
+		// the references are kept as they are when the values are not evaluated
+		require.Equal(t, "echo ${secret:PASSWORD} ${secret:TOKEN}", ret.Steps[0].CmdWithArgs)
+	})
+	t.Run("[Invalid] secrets", func(t *testing.T) {
+		tests := map[string]string{
+			"missing name":   "  - env: FOO",
+			"missing source": "  - name: FOO",
+			"two sources":    "  - name: FOO\n    env: FOO\n    file: /tmp/foo",
+			"duplicate name": "  - name: FOO\n    env: FOO\n  - name: FOO\n    env: BAR",
+			"reference":      "  - name: FOO\n    env: FOO\nenv:\n  - BAR: ${secret:FOO}",
+		}
+		for name, secrets := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := LoadYAML([]byte("secrets:\n" + secrets + "\nsteps:\n  - name: \"1\"\n    command: \"true\"\n"))
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestBuilder_BuildMatrix(t *testing.T) {
+	t.Run("static values", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: process
+    command: process.sh $REGION
+    matrix:
+      REGION: [us-east-1, eu-west-1]
+  - name: report
+    command: report.sh
+    depends: [process]
+`))
+		require.NoError(t, err)
+		require.Len(t, ret.Steps, 3)
+		require.Equal(t, "process[REGION=us-east-1]", ret.Steps[0].Name)
+		require.Equal(t, []string{"us-east-1"}, ret.Steps[0].Args)
+		require.Contains(t, ret.Steps[0].Variables, "REGION=us-east-1")
+		require.Equal(t, "process[REGION=eu-west-1]", ret.Steps[1].Name)
+		require.Equal(t, []string{
+			"process[REGION=us-east-1]",
+			"process[REGION=eu-west-1]",
+		}, ret.Steps[2].Depends)
+	})
+	t.Run("upstream output", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: list
+    command: list.sh
+    output: REGIONS
+  - name: process
+    command: process.sh $REGION
+    depends: [list]
+    matrix:
+      REGION: $REGIONS
+`))
+		require.NoError(t, err)
+		require.Len(t, ret.Steps, 2)
+		require.Equal(t, []MatrixVar{{Name: "REGION", Ref: "$REGIONS"}}, ret.Steps[1].Matrix)
+	})
+	t.Run("[Invalid] empty values", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: process
+    command: process.sh
+    matrix:
+      REGION: []
+`))
+		require.Error(t, err)
+	})
+	t.Run("[Invalid] value type", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: process
+    command: process.sh
+    matrix:
+      REGION: 1
+`))
+		require.Error(t, err)
+	})
+}
+
+func TestBuilder_BuildTimeout(t *testing.T) {
+	t.Run("timeoutSec", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    timeoutSec: 30
+`))
+		require.NoError(t, err)
+		require.Equal(t, time.Second*30, ret.Steps[0].Timeout)
+	})
+	t.Run("[Invalid] negative timeoutSec", func(t *testing.T) {
+		_, err := LoadYAML([]byte(`
+steps:
+  - name: "1"
+    command: "true"
+    timeoutSec: -1
+`))
+		require.Error(t, err)
+	})
+	t.Run("DAG timeoutSec", func(t *testing.T) {
+		ret, err := LoadYAML([]byte(`
+timeoutSec: 7200
+steps:
+  - name: "1"
+    command: "true"
+`))
+		require.NoError(t, err)
+		require.Equal(t, time.Hour*2, ret.Timeout)
+	})
+}
+
 func Test_convertMap(t *testing.T) {
 	t.Run("Convert map with string keys", func(t *testing.T) {
 		data := map[string]interface{}{
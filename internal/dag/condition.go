@@ -3,12 +3,28 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // Condition contains a condition and the expected value.
 // Conditions are evaluated and compared to the expected value.
 // The condition can be a command substitution or an environment variable.
 // The expected value must be a string without any substitutions.
+//
+// The expected value matches the actual value exactly unless it starts with
+// "expr:", in which case the rest is an expression matched against the
+// actual value:
+//   - A plain value matches the actual value exactly.
+//   - "re:<pattern>" matches the actual value against a regular expression.
+//   - "==", "!=", "<", "<=", ">" or ">=" followed by a value compares the
+//     actual value with it. "<", "<=", ">" and ">=" require numbers.
+//   - "!" or "not " negates the expression that follows.
+//   - "and" and "or" combine expressions. "and" binds tighter than "or".
+//
+// A value enclosed in double quotes is matched literally, e.g. "\"a or b\"".
 type Condition struct {
 	Condition string // Condition to evaluate
 	Expected  string // Expected value
@@ -17,23 +33,38 @@ type Condition struct {
 var (
 	errConditionNotMet = errors.New("condition was not met")
 	errEvalCondition   = errors.New("failed to evaluate condition")
+	errInvalidExpected = errors.New("invalid expected value")
+	errNotANumber      = errors.New("value is not a number")
 )
 
+// exprPrefix marks an expected value as an expression.
+const exprPrefix = "expr:"
+
 // eval evaluates the condition and returns the actual value.
 // It returns an error if the evaluation failed or the condition is invalid.
-func (c *Condition) eval() (string, error) {
-	return evaluateValue(c.Condition)
+func (c *Condition) eval(expand func(string) string) (string, error) {
+	return substituteCommands(expand(c.Condition))
 }
 
 // evalCondition evaluates a single condition and checks the result.
 // It returns an error if the condition was not met.
-func evalCondition(c *Condition) error {
-	actual, err := c.eval()
+func evalCondition(c *Condition, expand func(string) string) error {
+	actual, err := c.eval(expand)
+	if err != nil {
+		return fmt.Errorf("%w. Condition=%s Error=%v", errEvalCondition, c.Condition, err)
+	}
+
+	expr, err := c.expression()
+	if err != nil {
+		return fmt.Errorf("%w. Condition=%s Error=%v", errEvalCondition, c.Condition, err)
+	}
+
+	matched, err := expr.match(actual)
 	if err != nil {
 		return fmt.Errorf("%w. Condition=%s Error=%v", errEvalCondition, c.Condition, err)
 	}
 
-	if c.Expected != actual {
+	if !matched {
 		return fmt.Errorf("%w. Condition=%s Expected=%s Actual=%s", errConditionNotMet, c.Condition, c.Expected, actual)
 	}
 
@@ -43,11 +74,218 @@ func evalCondition(c *Condition) error {
 // EvalConditions evaluates a list of conditions and checks the results.
 // It returns an error if any of the conditions were not met.
 func EvalConditions(cond []*Condition) error {
+	return evalConditions(cond, os.ExpandEnv)
+}
+
+// EvalStepConditions evaluates the conditions of the step. The output
+// variables of the steps that have run are expanded in the conditions.
+func EvalStepConditions(step *Step, cond []*Condition) error {
+	return evalConditions(cond, step.ExpandEnv)
+}
+
+func evalConditions(cond []*Condition, expand func(string) string) error {
 	for _, c := range cond {
-		if err := evalCondition(c); err != nil {
+		if err := evalCondition(c, expand); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// validate checks that the expected value is a valid expression.
+func (c *Condition) validate() error {
+	_, err := c.expression()
+	return err
+}
+
+// expression returns the expected value as an expression. A value without
+// the expression prefix is matched literally.
+func (c *Condition) expression() (expression, error) {
+	s, ok := strings.CutPrefix(c.Expected, exprPrefix)
+	if !ok {
+		return literalExpr(c.Expected), nil
+	}
+	return parseExpression(strings.TrimSpace(s))
+}
+
+// expression is a parsed expected value of a condition.
+type expression interface {
+	match(actual string) (bool, error)
+}
+
+type (
+	orExpr      []expression
+	andExpr     []expression
+	notExpr     struct{ expr expression }
+	literalExpr string
+	regexExpr   struct{ re *regexp.Regexp }
+	compareExpr struct {
+		op      string
+		operand string
+	}
+)
+
+// comparisonOperators is ordered so that longer operators are matched first.
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseExpression parses the expected value of a condition.
+func parseExpression(s string) (expression, error) {
+	if parts := splitKeyword(s, "or"); len(parts) > 1 {
+		var ret orExpr
+		for _, p := range parts {
+			e, err := parseExpression(p)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+		return ret, nil
+	}
+	if parts := splitKeyword(s, "and"); len(parts) > 1 {
+		var ret andExpr
+		for _, p := range parts {
+			e, err := parseExpression(p)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+		return ret, nil
+	}
+	return parseTerm(s)
+}
+
+// parseTerm parses a single expression without "and" or "or".
+func parseTerm(s string) (expression, error) {
+	switch {
+	case strings.HasPrefix(s, "not "):
+		e, err := parseTerm(strings.TrimSpace(s[len("not "):]))
+		return notExpr{expr: e}, err
+	case strings.HasPrefix(s, "!") && !strings.HasPrefix(s, "!="):
+		e, err := parseTerm(strings.TrimSpace(s[1:]))
+		return notExpr{expr: e}, err
+	case strings.HasPrefix(s, "re:"):
+		re, err := regexp.Compile(unquote(s[len("re:"):]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errInvalidExpected, s, err)
+		}
+		return regexExpr{re: re}, nil
+	}
+	for _, op := range comparisonOperators {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		operand := unquote(strings.TrimSpace(s[len(op):]))
+		if op != "==" && op != "!=" {
+			if _, err := parseNumber(operand); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", errInvalidExpected, s, err)
+			}
+		}
+		return compareExpr{op: op, operand: operand}, nil
+	}
+	return literalExpr(unquote(s)), nil
+}
+
+// splitKeyword splits the string by the keyword surrounded by spaces.
+// Keywords in double quotes are ignored. The parts are trimmed if the
+// string is split.
+func splitKeyword(s, keyword string) []string {
+	var (
+		ret     []string
+		sep     = " " + keyword + " "
+		start   = 0
+		inQuote = false
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && strings.HasPrefix(s[i:], sep):
+			ret = append(ret, strings.TrimSpace(s[start:i]))
+			start = i + len(sep)
+			i = start - 1
+		}
+	}
+	if len(ret) == 0 {
+		return []string{s}
+	}
+	return append(ret, strings.TrimSpace(s[start:]))
+}
+
+// unquote removes the double quotes enclosing the string if any.
+func unquote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func parseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", errNotANumber, s)
+	}
+	return v, nil
+}
+
+func (e orExpr) match(actual string) (bool, error) {
+	for _, expr := range e {
+		matched, err := expr.match(actual)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+func (e andExpr) match(actual string) (bool, error) {
+	for _, expr := range e {
+		matched, err := expr.match(actual)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e notExpr) match(actual string) (bool, error) {
+	matched, err := e.expr.match(actual)
+	return !matched, err
+}
+
+func (e literalExpr) match(actual string) (bool, error) {
+	return string(e) == actual, nil
+}
+
+func (e regexExpr) match(actual string) (bool, error) {
+	return e.re.MatchString(actual), nil
+}
+
+func (e compareExpr) match(actual string) (bool, error) {
+	want, wantErr := parseNumber(e.operand)
+	got, gotErr := parseNumber(actual)
+	if wantErr != nil || gotErr != nil {
+		switch e.op {
+		case "==":
+			return actual == e.operand, nil
+		case "!=":
+			return actual != e.operand, nil
+		}
+		return false, gotErr
+	}
+	switch e.op {
+	case "==":
+		return got == want, nil
+	case "!=":
+		return got != want, nil
+	case "<":
+		return got < want, nil
+	case "<=":
+		return got <= want, nil
+	case ">":
+		return got > want, nil
+	default:
+		return got >= want, nil
+	}
+}
@@ -68,3 +68,59 @@ func TestCondition_Eval(t *testing.T) {
 		})
 	}
 }
+
+func TestCondition_Expression(t *testing.T) {
+	tests := []struct {
+		actual   string
+		expected string
+		want     bool
+	}{
+		{actual: "ok", expected: "ok", want: true},
+		{actual: "ok", expected: "ng", want: false},
+		{actual: "rock and roll", expected: "rock and roll", want: true},
+		{actual: "rock", expected: "rock and roll", want: false},
+		{actual: "10", expected: "> 9", want: false},
+		{actual: "re:^err", expected: "re:^err", want: true},
+		{actual: "a and b", expected: `expr:"a and b"`, want: true},
+		{actual: "2024-01-01", expected: `expr:re:^\d{4}-\d{2}-\d{2}$`, want: true},
+		{actual: "ready", expected: "expr:re:^err", want: false},
+		{actual: "ready", expected: "expr:!re:^err", want: true},
+		{actual: "ready", expected: "expr:not ready", want: false},
+		{actual: "10", expected: "expr:> 9", want: true},
+		{actual: "10", expected: "expr:>= 10.5", want: false},
+		{actual: "10", expected: "expr:== 10.0", want: true},
+		{actual: "ok", expected: "expr:!= ng", want: true},
+		{actual: "-1", expected: "expr:< 0", want: true},
+		{actual: "15", expected: "expr:>= 10 and < 20", want: true},
+		{actual: "25", expected: "expr:>= 10 and < 20", want: false},
+		{actual: "done", expected: "expr:ready or done", want: true},
+		{actual: "3", expected: "expr:< 0 or > 2 and < 5", want: true},
+		{actual: "1", expected: "expr:< 0 or > 2 and < 5", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			err := EvalConditions([]*Condition{
+				{Condition: tt.actual, Expected: tt.expected},
+			})
+			if tt.want {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, errConditionNotMet)
+			}
+		})
+	}
+}
+
+func TestCondition_InvalidExpression(t *testing.T) {
+	for _, expected := range []string{"expr:re:[", "expr:> abc", "expr:ok and <= x"} {
+		t.Run(expected, func(t *testing.T) {
+			c := &Condition{Condition: "1", Expected: expected}
+			require.ErrorIs(t, c.validate(), errInvalidExpected)
+			require.ErrorIs(t, EvalConditions([]*Condition{c}), errEvalCondition)
+		})
+	}
+	t.Run("NotANumber", func(t *testing.T) {
+		err := EvalConditions([]*Condition{{Condition: "abc", Expected: "expr:> 1"}})
+		require.ErrorIs(t, err, errEvalCondition)
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"crypto/md5"
 	"fmt"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/util"
 
 	"github.com/robfig/cron/v3"
 )
@@ -58,6 +60,7 @@ type DAG struct {
 	RestartSchedule   []*Schedule   // RestartSchedule is the restart schedule of the DAG.
 	Description       string        // Description is the description of the DAG. optional.
 	Env               []string      // Env contains a list of environment variables to be set before running the DAG.
+	Secrets           []Secret      // Secrets contains the secrets to be set as environment variables. optional.
 	LogDir            string        // LogDir is the directory where the logs are stored.
 	HandlerOn         HandlerOn     // HandlerOn contains the steps to be executed on different events.
 	Steps             []Step        // Steps contains the list of steps in the DAG.
@@ -70,12 +73,42 @@ type DAG struct {
 	HistRetentionDays int           // HistRetentionDays is the number of days to keep the history.
 	Preconditions     []*Condition  // Preconditions contains the conditions to be met before running the DAG.
 	MaxActiveRuns     int           // MaxActiveRuns specifies the maximum concurrent steps to run in an execution.
+	MaxConcurrentRuns int           // MaxConcurrentRuns specifies the maximum number of runs of the DAG at the same time. The default is 1.
+	Priority          int           // Priority is the priority of the runs of the DAG in the run queue. The runs with a higher priority are started first.
+	OverlapPolicy     OverlapPolicy // OverlapPolicy determines what happens to a scheduled run while the DAG is running as many runs as it allows.
+	Catchup           bool          // Catchup specifies whether the runs missed while the scheduler was down are started when it starts.
 	Params            []string      // Params contains the list of parameters to be passed to the DAG.
 	DefaultParams     string        // DefaultParams contains the default parameters to be passed to the DAG.
+	ParamSchema       []Param       // ParamSchema contains the typed parameters of the DAG. optional.
 	MaxCleanUpTime    time.Duration // MaxCleanUpTime is the maximum time to wait for cleanup when the DAG is stopped.
+	Timeout           time.Duration // Timeout is the maximum time the DAG is allowed to run. The DAG is stopped when it is exceeded.
 	Tags              []string      // Tags contains the list of tags for the DAG. optional.
 }
 
+// OverlapPolicy determines what happens to a scheduled run of the DAG
+// while the DAG is running as many runs as it allows.
+type OverlapPolicy string
+
+const (
+	OverlapPolicySkip           OverlapPolicy = "skip"           // the scheduled run is skipped (default)
+	OverlapPolicyQueue          OverlapPolicy = "queue"          // the scheduled run starts when a current run finishes
+	OverlapPolicyCancelPrevious OverlapPolicy = "cancelPrevious" // the current runs are canceled and the scheduled run starts
+	OverlapPolicyAllow          OverlapPolicy = "allow"          // the scheduled run always waits in the queue without a limit
+)
+
+// EnvScheduledTime is the environment variable that has the schedule time
+// of a scheduled run.
+const EnvScheduledTime = "DAG_SCHEDULED_TIME"
+
+// IsValid returns true if the overlap policy is empty or one of the known policies.
+func (p OverlapPolicy) IsValid() bool {
+	switch p {
+	case "", OverlapPolicySkip, OverlapPolicyQueue, OverlapPolicyCancelPrevious, OverlapPolicyAllow:
+		return true
+	}
+	return false
+}
+
 // setup sets the default values for the DAG.
 func (d *DAG) setup() {
 	// LogDir is the directory where the logs are stored.
@@ -119,6 +152,12 @@ func (d *DAG) setup() {
 	}
 }
 
+// RunLimit returns the maximum number of runs of the DAG at the same time.
+// The overlap policy does not change it.
+func (d *DAG) RunLimit() int {
+	return max(d.MaxConcurrentRuns, 1)
+}
+
 // HasTag checks if the DAG has the given tag.
 func (d *DAG) HasTag(tag string) bool {
 	for _, t := range d.Tags {
@@ -130,16 +169,36 @@ func (d *DAG) HasTag(tag string) bool {
 	return false
 }
 
-// SockAddr returns the unix socket address for the DAG.
-// The address is used to communicate with the agent process.
-// TODO: It needs to be unique for each process so that multiple processes can run in parallel.
-func (d *DAG) SockAddr() string {
+// SockAddr returns the unix socket address for the run of the DAG with the
+// request ID. The address is used to communicate with the agent process.
+// It is unique for each run so that multiple runs can be in parallel.
+func (d *DAG) SockAddr(requestId string) string {
+	return fmt.Sprintf("%s-%s.sock", d.sockAddrPrefix(), util.TruncString(requestId, 8))
+}
+
+// SockAddrPattern returns the glob pattern that matches the unix socket
+// addresses of all the runs of the DAG.
+func (d *DAG) SockAddrPattern() string {
+	return fmt.Sprintf("%s-*.sock", d.sockAddrPrefix())
+}
+
+// SockAddrPatternAll returns the glob pattern that matches the unix socket
+// addresses of all the runs of all the DAGs.
+func SockAddrPatternAll() string {
+	return path.Join("/tmp", "@dagu-*.sock")
+}
+
+// globMetaRegex matches the characters that have a special meaning in a glob pattern.
+var globMetaRegex = regexp.MustCompile(`[*?[\\\]]`)
+
+func (d *DAG) sockAddrPrefix() string {
 	s := strings.ReplaceAll(d.Location, " ", "_")
 	name := strings.Replace(path.Base(s), path.Ext(path.Base(s)), "", 1)
+	name = globMetaRegex.ReplaceAllString(name, "_")
 	h := md5.New()
 	_, _ = h.Write([]byte(s))
 	bs := h.Sum(nil)
-	return path.Join("/tmp", fmt.Sprintf("@dagu-%s-%x.sock", name, bs))
+	return path.Join("/tmp", fmt.Sprintf("@dagu-%s-%x", name, bs))
 }
 
 // String implements the Stringer interface.
@@ -3,6 +3,7 @@ package dag
 import (
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/dagu-dev/dagu/internal/config"
@@ -39,6 +40,25 @@ func TestDAG_String(t *testing.T) {
 func TestDAG_SockAddr(t *testing.T) {
 	t.Run("Unix Socket", func(t *testing.T) {
 		d := &DAG{Location: "testdata/testDag.yml"}
-		require.Regexp(t, `^/tmp/@dagu-testDag-[0-9a-f]+\.sock$`, d.SockAddr())
+		require.Regexp(t, `^/tmp/@dagu-testDag-[0-9a-f]+-12345678\.sock$`, d.SockAddr("1234567890"))
+	})
+	t.Run("Unique for each run", func(t *testing.T) {
+		d := &DAG{Location: "testdata/testDag.yml"}
+		require.NotEqual(t, d.SockAddr("run1"), d.SockAddr("run2"))
+		for _, reqId := range []string{"run1", "run2"} {
+			matched, err := filepath.Match(d.SockAddrPattern(), d.SockAddr(reqId))
+			require.NoError(t, err)
+			require.True(t, matched)
+		}
+		other := &DAG{Location: "testdata/testDag2.yml"}
+		matched, err := filepath.Match(d.SockAddrPattern(), other.SockAddr("run1"))
+		require.NoError(t, err)
+		require.False(t, matched)
+	})
+	t.Run("Glob characters in the name", func(t *testing.T) {
+		d := &DAG{Location: "testdata/test[1]*.yml"}
+		matched, err := filepath.Match(d.SockAddrPattern(), d.SockAddr("run1"))
+		require.NoError(t, err)
+		require.True(t, matched)
 	})
 }
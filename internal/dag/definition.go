@@ -10,6 +10,7 @@ type definition struct {
 	Schedule          any
 	LogDir            string
 	Env               any
+	Secrets           []*secretDef
 	HandlerOn         handerOnDef
 	Functions         []*funcDef
 	Steps             []*stepDef
@@ -22,9 +23,18 @@ type definition struct {
 	HistRetentionDays *int
 	Preconditions     []*conditionDef
 	MaxActiveRuns     int
-	Params            string
+	MaxConcurrentRuns int
+	Priority          int
+	OverlapPolicy     string
+	Catchup           bool
+	Params            any
 	MaxCleanUpTimeSec *int
+	TimeoutSec        int
 	Tags              string
+	Include           []string
+
+	// secretRefs are the names of the secrets referred to as "${secret:NAME}".
+	secretRefs []string
 }
 
 type conditionDef struct {
@@ -50,12 +60,18 @@ type stepDef struct {
 	Stderr        string
 	Output        string
 	Depends       []string
+	TriggerRule   string
+	Matrix        map[string]any
+	GenerateSteps bool
 	ContinueOn    *continueOnDef
 	RetryPolicy   *retryPolicyDef
 	RepeatPolicy  *repeatPolicyDef
 	MailOnError   bool
 	Preconditions []*conditionDef
+	WaitFor       *waitForDef
 	SignalOnStop  *string
+	TimeoutSec    int
+	Pool          string
 	Env           string
 	Call          *callFuncDef
 	Run           string // Run is a sub workflow to run
@@ -73,6 +89,11 @@ type callFuncDef struct {
 	Args     map[string]interface{}
 }
 
+type waitForDef struct {
+	PollIntervalSec int
+	TimeoutSec      int
+}
+
 type continueOnDef struct {
 	Failure bool
 	Skipped bool
@@ -81,11 +102,19 @@ type continueOnDef struct {
 type repeatPolicyDef struct {
 	Repeat      bool
 	IntervalSec int
+	Condition   string
+	Expected    string
+	ExitCode    []int
+	Limit       int
 }
 
 type retryPolicyDef struct {
-	Limit       int
-	IntervalSec int
+	Limit          int
+	IntervalSec    int
+	Backoff        float64
+	MaxIntervalSec int
+	JitterSec      int
+	ExitCodes      []int
 }
 
 type smtpConfigDef struct {
@@ -22,3 +22,26 @@ func (e *errorList) Error() string {
 	}
 	return strings.Join(errStrings, "; ")
 }
+
+// Unwrap returns the errors in the list.
+func (e *errorList) Unwrap() []error {
+	return *e
+}
+
+// fieldError is an error on a field of the definition. The path to the
+// field such as "steps[1]" is used to find the position of the error in
+// the YAML file.
+type fieldError struct {
+	path string
+	err  error
+}
+
+// Error implements the error interface.
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,101 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/util"
+)
+
+var (
+	errIncludeNotFound = errors.New("included file not found")
+	errIncludeCycle    = errors.New("include cycle detected")
+	errIncludeSteps    = errors.New("included file must not define steps")
+)
+
+// loadIncludes loads the fragments included by the definition and merges
+// them into the destination DAG in the order they are listed. A fragment
+// can include other fragments. The functions of the fragments are added to
+// the definition unless it defines a function with the same name.
+// The file is the file of the definition, and the stack is the list of the
+// files that are being loaded to detect include cycles.
+func loadIncludes(dst *DAG, def *definition, file string, opts buildOpts, stack []string) error {
+	// Fragments must load all the data like the base configuration.
+	buildOpts := opts
+	buildOpts.metadataOnly = false
+
+	for _, name := range def.Include {
+		inc, err := resolveInclude(name, file)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(stack, inc) {
+			return fmt.Errorf("%w: %s", errIncludeCycle, strings.Join(append(stack, inc), " -> "))
+		}
+
+		raw, err := readFile(inc)
+		if err != nil {
+			return err
+		}
+		incDef, err := decodeDefinition(raw, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", inc, err)
+		}
+		if len(incDef.Steps) > 0 {
+			return fmt.Errorf("%w: %s", errIncludeSteps, inc)
+		}
+		if err := loadIncludes(dst, incDef, inc, opts, append(stack, inc)); err != nil {
+			return err
+		}
+
+		b := &builder{opts: buildOpts}
+		fragment, err := b.build(incDef, dst.Env)
+		if err != nil {
+			return fmt.Errorf("%s: %w", inc, err)
+		}
+		if err := merge(dst, fragment); err != nil {
+			return fmt.Errorf("%s: %w", inc, err)
+		}
+
+		for _, fn := range incDef.Functions {
+			if !slices.ContainsFunc(def.Functions, func(f *funcDef) bool { return f.Name == fn.Name }) {
+				def.Functions = append(def.Functions, fn)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveInclude returns the absolute path to the included file.
+// A relative path is resolved against the directory of the including file
+// first and then against the DAGs directory.
+func resolveInclude(name, file string) (string, error) {
+	if filepath.IsAbs(name) {
+		if util.FileExists(name) {
+			return name, nil
+		}
+		return "", fmt.Errorf("%w: %s", errIncludeNotFound, name)
+	}
+
+	var dirs []string
+	if file != "" {
+		dirs = append(dirs, filepath.Dir(file))
+	}
+	if dagsDir := config.Get().DAGs; dagsDir != "" {
+		dirs = append(dirs, dagsDir)
+	}
+	for _, dir := range dirs {
+		inc := filepath.Join(dir, name)
+		if util.FileExists(inc) {
+			return filepath.Abs(inc)
+		}
+	}
+	if file != "" {
+		return "", fmt.Errorf("%w: %s (included from %s)", errIncludeNotFound, name, file)
+	}
+	return "", fmt.Errorf("%w: %s", errIncludeNotFound, name)
+}
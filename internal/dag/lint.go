@@ -0,0 +1,633 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the severity of a problem found in a DAG file.
+type Severity string
+
+const (
+	// SeverityError is a problem that prevents the DAG from running.
+	SeverityError Severity = "error"
+	// SeverityWarning is a problem that is likely a mistake.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a DAG file.
+// The line and the column are 1-based, and they are 0 if unknown.
+type Diagnostic struct {
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String returns the diagnostic in the form of "file:line:column: severity: message".
+// The file is omitted for a spec that is not read from a file.
+func (d Diagnostic) String() string {
+	var pos string
+	if d.Line > 0 {
+		pos = fmt.Sprintf("%d:%d", d.Line, d.Column)
+	}
+	if d.File != "" {
+		pos = strings.TrimSuffix(d.File+":"+pos, ":")
+	}
+	if pos == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+}
+
+// builtinVariables are the environment variables that are set for the steps
+// when they run.
+var builtinVariables = []string{"DAGU_OUTPUT", EnvScheduledTime}
+
+var (
+	// yamlLineRegex matches the line number in a YAML syntax error.
+	yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+	// decodeErrorRegex matches an error of decoding a field of the definition
+	// such as "'Steps[0].TimeoutSec' expected type 'int'".
+	decodeErrorRegex = regexp.MustCompile(`^'([^']*)' (.*)$`)
+	// invalidKeysRegex matches an error of unknown keys of the definition.
+	invalidKeysRegex = regexp.MustCompile(`^has invalid keys: (.*)$`)
+	// pathElemRegex matches an element of a path such as "steps" or "[0]".
+	pathElemRegex = regexp.MustCompile(`([^.\[\]]+)|\[(\d+)\]`)
+	// variableRegex matches a reference to a variable such as "$FOO" or "${FOO}".
+	variableRegex = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+	// shellVariableRegex matches a variable defined in a shell command such as
+	// "FOO=bar", "for FOO in" or "read FOO".
+	shellVariableRegex = regexp.MustCompile(`(?:^|[\s;&|(])([A-Za-z_][A-Za-z0-9_]*)=|\bfor\s+([A-Za-z_][A-Za-z0-9_]*)\s+in\b|\bread\s+(?:-\w+\s+)*([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// Lint checks the DAG file and returns the problems found in it.
+// The file is loaded through the builder with the base configuration,
+// and every error is reported with its position in the file. The file is
+// also validated against the JSON schema of the DAG files, and the warnings
+// about unknown keys, dependencies on missing steps, unreachable steps,
+// undefined variables and unused functions are reported. The resource
+// pools that the steps use must be defined in the config.
+func Lint(base, file string) []Diagnostic {
+	l := &linter{file: file, schema: true, pools: map[string]bool{}}
+	for name := range config.Get().Pools {
+		l.pools[strings.ToLower(name)] = true
+	}
+	path, err := prepareFilepath(file)
+	if err != nil {
+		l.report(nil, SeverityError, err.Error())
+		return l.diags
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.report(nil, SeverityError, err.Error())
+		return l.diags
+	}
+
+	opts := buildOpts{base: base, noEval: true}
+	l.lint(data, opts, func() (*DAG, error) { return loadDAG(path, opts) })
+	return l.sorted()
+}
+
+// LintYAML checks the DAG spec in YAML in the same way as Lint.
+// The spec is loaded without the base configuration.
+func LintYAML(data []byte) []Diagnostic {
+	l := &linter{schema: true}
+	l.lint(data, buildOpts{noEval: true}, func() (*DAG, error) { return LoadYAML(data) })
+	return l.sorted()
+}
+
+// ValidationError is the error of a DAG spec that has errors.
+// The diagnostics include the warnings as well.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+// Error implements the error interface.
+// It returns the errors in the diagnostics separated by a semicolon.
+func (e *ValidationError) Error() string {
+	var errs []string
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.String())
+		}
+	}
+	return "invalid DAG spec: " + strings.Join(errs, "; ")
+}
+
+// ValidateYAMLAt validates the DAG spec in YAML before it is saved to the file.
+// The included files and the base configurations of the directories are
+// resolved against the location of the file. The spec is not validated against the JSON schema so that only the specs
+// that can not be loaded are rejected. The checks are run on the DAG if the
+// spec has no errors, and their errors are reported at the steps.
+// It returns a *ValidationError if the spec or any of the checks has errors.
+func ValidateYAMLAt(file string, data []byte, checks ...func(*DAG) error) error {
+	l := &linter{}
+	opts := buildOpts{noEval: true}
+	d := l.lint(data, opts, func() (*DAG, error) { return loadYAMLAt(file, data, opts) })
+	if d != nil && !l.hasErrors() {
+		for _, check := range checks {
+			if err := check(d); err != nil {
+				l.report(l.lookup("steps"), SeverityError, err.Error())
+			}
+		}
+	}
+	if l.hasErrors() {
+		return &ValidationError{Diagnostics: l.sorted()}
+	}
+	return nil
+}
+
+// linter collects the problems found in a DAG file.
+type linter struct {
+	file  string
+	root  *yaml.Node // root is the top-level mapping of the file.
+	diags []Diagnostic
+	// errPaths are the paths to the fields that the builder reported errors on.
+	errPaths []string
+	// schema specifies whether to validate the spec against the JSON schema.
+	schema bool
+	// pools are the lowercased names of the resource pools defined in the
+	// config. The pools of the steps are not checked if it is nil.
+	pools map[string]bool
+}
+
+// lint checks the DAG spec and returns the DAG if it is loaded.
+// The load function loads the DAG from the spec with the options.
+func (l *linter) lint(data []byte, opts buildOpts, load func() (*DAG, error)) *DAG {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		l.reportSyntaxError(err)
+		return nil
+	}
+	if len(doc.Content) > 0 {
+		l.root = resolveAlias(doc.Content[0])
+		if l.root.Kind != yaml.MappingNode {
+			l.report(l.root, SeverityError, fmt.Sprintf("DAG: expected object, got %s", nodeType(l.root)))
+			return nil
+		}
+	}
+
+	d, loadErr := load()
+	if loadErr != nil {
+		l.reportLoadError(loadErr)
+	}
+
+	raw, err := unmarshalData(data)
+	if err != nil {
+		l.checkSchema()
+		return d
+	}
+	def, err := decodeIgnoringUnknownKeys(raw)
+	if err != nil {
+		l.checkSchema()
+		return d
+	}
+
+	// The builder does not run if the file has unknown keys,
+	// so the definition without them is built to report the rest.
+	var merr *mapstructure.Error
+	if errors.As(loadErr, &merr) {
+		b := &builder{opts: opts}
+		if _, err := b.build(def, nil); err != nil {
+			l.reportLoadError(err)
+		}
+	}
+
+	l.checkSchema()
+	l.checkDependencies(def)
+	l.checkFunctions(def)
+	l.checkPools(def)
+
+	// The variables are checked only if the DAG is loaded since
+	// the environment of the base configuration is required.
+	if d != nil {
+		l.checkVariables(d, def)
+	}
+	return d
+}
+
+// sorted returns the diagnostics sorted by the position.
+func (l *linter) sorted() []Diagnostic {
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].Line != l.diags[j].Line {
+			return l.diags[i].Line < l.diags[j].Line
+		}
+		return l.diags[i].Column < l.diags[j].Column
+	})
+	return l.diags
+}
+
+// hasErrors returns true if any error is reported.
+func (l *linter) hasErrors() bool {
+	return slices.ContainsFunc(l.diags, func(d Diagnostic) bool {
+		return d.Severity == SeverityError
+	})
+}
+
+// decodeIgnoringUnknownKeys decodes the configuration map into a definition
+// ignoring the unknown keys.
+func decodeIgnoringUnknownKeys(cm map[string]any) (*definition, error) {
+	def := &definition{}
+	md, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:  def,
+		TagName: "",
+	})
+	return def, md.Decode(cm)
+}
+
+// report adds a problem at the position of the node.
+func (l *linter) report(node *yaml.Node, severity Severity, message string) {
+	d := Diagnostic{File: l.file, Severity: severity, Message: message}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+	l.diags = append(l.diags, d)
+}
+
+// reportAt adds an error at the field of the path.
+func (l *linter) reportAt(path, message string) {
+	l.errPaths = append(l.errPaths, path)
+	l.report(l.lookup(path), SeverityError, message)
+}
+
+// reported returns true if an error is already reported at the field of
+// the path or the field that contains it.
+func (l *linter) reported(path string) bool {
+	return slices.ContainsFunc(l.errPaths, func(p string) bool {
+		return path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[")
+	})
+}
+
+// reportSyntaxError reports an error of parsing the YAML file.
+func (l *linter) reportSyntaxError(err error) {
+	d := Diagnostic{File: l.file, Severity: SeverityError, Message: err.Error()}
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Column = 1
+	}
+	l.diags = append(l.diags, d)
+}
+
+// reportLoadError reports the errors of loading the DAG.
+// The errors of the file itself are reported at the fields they are about,
+// and the other errors such as the ones of the included files are reported
+// for the whole file.
+func (l *linter) reportLoadError(err error) {
+	var (
+		errs *errorList
+		merr *mapstructure.Error
+	)
+	switch {
+	case errors.As(err, &errs) && error(errs) == err:
+		for _, e := range flattenErrors(errs) {
+			var fe *fieldError
+			if errors.As(e, &fe) {
+				l.reportAt(fe.path, fmt.Sprintf("%s: %s", fe.path, fe.err))
+				continue
+			}
+			l.report(nil, SeverityError, e.Error())
+		}
+
+	case errors.As(err, &merr) && error(merr) == err:
+		for _, msg := range merr.Errors {
+			l.reportDecodeError(msg)
+		}
+
+	case errors.Is(err, errIncludeNotFound), errors.Is(err, errIncludeCycle), errors.Is(err, errIncludeSteps):
+		l.report(l.lookup("include"), SeverityError, err.Error())
+
+	default:
+		l.report(nil, SeverityError, err.Error())
+	}
+}
+
+// reportDecodeError reports an error of decoding the definition.
+func (l *linter) reportDecodeError(msg string) {
+	m := decodeErrorRegex.FindStringSubmatch(msg)
+	if m == nil {
+		l.report(nil, SeverityError, msg)
+		return
+	}
+	path, detail := decodePath(m[1]), m[2]
+	if keys := invalidKeysRegex.FindStringSubmatch(detail); keys != nil {
+		for _, key := range strings.Split(keys[1], ", ") {
+			keyPath := joinPath(path, key)
+			l.reportAt(keyPath, fmt.Sprintf("unknown key %q", keyPath))
+		}
+		return
+	}
+	l.reportAt(path, fmt.Sprintf("%s: %s", path, detail))
+}
+
+// checkSchema validates the file against the JSON schema of the DAG files.
+func (l *linter) checkSchema() {
+	if !l.schema || l.root == nil {
+		return
+	}
+	v, err := loadDAGSchema()
+	if err != nil {
+		l.report(nil, SeverityError, err.Error())
+		return
+	}
+	for _, e := range v.validate(l.root, l.root, v.root, "") {
+		// The builder reports most of the problems as well.
+		if l.reported(e.path) {
+			continue
+		}
+		severity := SeverityError
+		if e.unknown {
+			severity = SeverityWarning
+		}
+		l.report(e.node, severity, e.message)
+	}
+}
+
+// checkDependencies reports the dependencies on missing steps and the steps
+// that never run because they depend on a missing step or a cycle.
+func (l *linter) checkDependencies(def *definition) {
+	// The names of the generated steps are not known until they run.
+	if slices.ContainsFunc(def.Steps, func(s *stepDef) bool { return s.GenerateSteps }) {
+		return
+	}
+
+	steps := map[string]*stepDef{}
+	for _, s := range def.Steps {
+		steps[s.Name] = s
+	}
+
+	const (
+		visiting = iota + 1
+		reachable
+		unreachable
+	)
+	state := map[string]int{}
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		s, ok := steps[name]
+		if !ok {
+			return false
+		}
+		switch state[name] {
+		case visiting:
+			return false
+		case reachable:
+			return true
+		case unreachable:
+			return false
+		}
+		state[name] = visiting
+		ok = true
+		for _, dep := range s.Depends {
+			if !visit(dep) {
+				ok = false
+			}
+		}
+		if ok {
+			state[name] = reachable
+		} else {
+			state[name] = unreachable
+		}
+		return ok
+	}
+
+	for i, s := range def.Steps {
+		var missing bool
+		for j, dep := range s.Depends {
+			if _, ok := steps[dep]; !ok {
+				missing = true
+				l.report(l.lookup(fmt.Sprintf("steps[%d].depends[%d]", i, j)), SeverityWarning,
+					fmt.Sprintf("step %q depends on a missing step %q", s.Name, dep))
+			}
+		}
+		if visit(s.Name) || missing {
+			continue
+		}
+		reason := "it depends on a step that never runs"
+		if dependsOn(steps, s.Name, s.Name, map[string]bool{}) {
+			reason = "it is in a dependency cycle"
+		}
+		l.report(l.lookup(fmt.Sprintf("steps[%d]", i)), SeverityWarning,
+			fmt.Sprintf("step %q is unreachable: %s", s.Name, reason))
+	}
+}
+
+// dependsOn returns true if the step depends on the target directly or
+// indirectly.
+func dependsOn(steps map[string]*stepDef, name, target string, seen map[string]bool) bool {
+	s, ok := steps[name]
+	if !ok {
+		return false
+	}
+	for _, dep := range s.Depends {
+		if dep == target {
+			return true
+		}
+		if !seen[dep] {
+			seen[dep] = true
+			if dependsOn(steps, dep, target, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkVariables reports the references to the variables that are not
+// defined in the DAG, its parameters or the environment of the process.
+// The scripts are not checked since they often define their own variables.
+func (l *linter) checkVariables(d *DAG, def *definition) {
+	defined := map[string]bool{}
+	for _, name := range builtinVariables {
+		defined[name] = true
+	}
+	for _, env := range d.Env {
+		defined[strings.SplitN(env, "=", 2)[0]] = true
+	}
+	for _, p := range d.Params {
+		if name, _, found := strings.Cut(p, "="); found {
+			defined[name] = true
+		}
+	}
+	for _, p := range d.ParamSchema {
+		defined[p.Name] = true
+	}
+	for _, s := range d.Secrets {
+		defined[s.Name] = true
+	}
+
+	steps := stepDefsWithPath(def)
+	for _, s := range steps {
+		if s.def.Output != "" {
+			defined[s.def.Output] = true
+		}
+	}
+
+	for _, s := range steps {
+		local := map[string]bool{}
+		for name := range s.def.Matrix {
+			local[name] = true
+		}
+		fields := map[string][]string{
+			"dir":    {s.def.Dir},
+			"stdout": {s.def.Stdout},
+			"stderr": {s.def.Stderr},
+			"run":    {s.def.Run},
+			"params": {s.def.Params},
+		}
+		switch cmd := s.def.Command.(type) {
+		case string:
+			fields["command"] = []string{cmd}
+		case []any:
+			for _, c := range cmd {
+				fields["command"] = append(fields["command"], fmt.Sprint(c))
+			}
+		}
+		for _, c := range fields["command"] {
+			for _, m := range shellVariableRegex.FindAllStringSubmatch(c, -1) {
+				local[m[1]+m[2]+m[3]] = true
+			}
+		}
+
+		for _, field := range []string{"command", "dir", "stdout", "stderr", "run", "params"} {
+			for _, value := range fields[field] {
+				for _, m := range variableRegex.FindAllStringSubmatch(value, -1) {
+					name := m[1] + m[2]
+					if defined[name] || local[name] {
+						continue
+					}
+					if _, ok := os.LookupEnv(name); ok {
+						continue
+					}
+					local[name] = true // report once per step
+					l.report(l.lookup(joinPath(s.path, field)), SeverityWarning,
+						fmt.Sprintf("step %q refers to an undefined variable %q", s.def.Name, name))
+				}
+			}
+		}
+	}
+}
+
+// checkFunctions reports the functions that are not called by any step.
+func (l *linter) checkFunctions(def *definition) {
+	called := map[string]bool{}
+	for _, s := range stepDefsWithPath(def) {
+		if s.def.Call != nil {
+			called[s.def.Call.Function] = true
+		}
+	}
+	for i, fn := range def.Functions {
+		if !called[fn.Name] {
+			l.report(l.lookup(fmt.Sprintf("functions[%d]", i)), SeverityWarning,
+				fmt.Sprintf("function %q is never called", fn.Name))
+		}
+	}
+}
+
+// checkPools reports the steps that use a resource pool that is not
+// defined in the config. The step fails when it runs otherwise.
+func (l *linter) checkPools(def *definition) {
+	if l.pools == nil {
+		return
+	}
+	for _, s := range stepDefsWithPath(def) {
+		if s.def.Pool != "" && !l.pools[strings.ToLower(s.def.Pool)] {
+			l.report(l.lookup(s.path+".pool"), SeverityError,
+				fmt.Sprintf("pool %q is not defined in the config", s.def.Pool))
+		}
+	}
+}
+
+// lookup returns the node at the path such as "steps[1].command" in the file.
+// If the path is not found, the deepest node found on the way is returned.
+// For a key of a mapping, the node of the key is returned so that it points
+// to the line of the key.
+func (l *linter) lookup(path string) *yaml.Node {
+	if l.root == nil {
+		return nil
+	}
+	cur, ret := l.root, l.root
+	for _, m := range pathElemRegex.FindAllStringSubmatch(path, -1) {
+		cur = resolveAlias(cur)
+		if m[1] != "" {
+			if cur.Kind != yaml.MappingNode {
+				break
+			}
+			key := lookupKey(cur, m[1])
+			if key == nil {
+				break
+			}
+			idx := slices.Index(cur.Content, key)
+			cur, ret = cur.Content[idx+1], key
+			continue
+		}
+		i, _ := strconv.Atoi(m[2])
+		if cur.Kind != yaml.SequenceNode || i >= len(cur.Content) {
+			break
+		}
+		cur = cur.Content[i]
+		ret = cur
+	}
+	return ret
+}
+
+// decodePath converts the path of a field of the definition such as
+// "Steps[0].TimeoutSec" to the path of the key in the file.
+func decodePath(path string) string {
+	return pathElemRegex.ReplaceAllStringFunc(path, func(elem string) string {
+		if strings.HasPrefix(elem, "[") {
+			return elem
+		}
+		return strings.ToLower(elem[:1]) + elem[1:]
+	})
+}
+
+// stepDefWithPath is a step definition with the path to it in the file.
+type stepDefWithPath struct {
+	path string
+	def  *stepDef
+}
+
+// stepDefsWithPath returns the steps and the handlers of the definition.
+func stepDefsWithPath(def *definition) []stepDefWithPath {
+	var ret []stepDefWithPath
+	for i, s := range def.Steps {
+		ret = append(ret, stepDefWithPath{path: fmt.Sprintf("steps[%d]", i), def: s})
+	}
+	for name, s := range map[string]*stepDef{
+		"handlerOn.exit":    def.HandlerOn.Exit,
+		"handlerOn.success": def.HandlerOn.Success,
+		"handlerOn.failure": def.HandlerOn.Failure,
+		"handlerOn.cancel":  def.HandlerOn.Cancel,
+	} {
+		if s != nil {
+			ret = append(ret, stepDefWithPath{path: name, def: s})
+		}
+	}
+	return ret
+}
+
+// flattenErrors returns the errors in the error list and the nested lists.
+func flattenErrors(errs *errorList) []error {
+	var ret []error
+	for _, err := range *errs {
+		var nested *errorList
+		if errors.As(err, &nested) && error(nested) == err {
+			ret = append(ret, flattenErrors(nested)...)
+			continue
+		}
+		ret = append(ret, err)
+	}
+	return ret
+}
@@ -0,0 +1,142 @@
+package dag
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	type diagnostic struct {
+		line     int
+		severity Severity
+		message  string
+	}
+	tests := []struct {
+		name     string
+		file     string
+		expected []diagnostic
+	}{
+		{
+			name: "Valid",
+			file: path.Join(testdataDir, "default.yaml"),
+		},
+		{
+			name: "ParseError",
+			file: path.Join(testdataDir, "err_parse.yaml"),
+			expected: []diagnostic{
+				{1, SeverityError, "expected object, got string"},
+			},
+		},
+		{
+			name: "Errors",
+			file: path.Join(testdataDir, "lint_errors.yaml"),
+			expected: []diagnostic{
+				{1, SeverityError, "invalid schedule"},
+				{2, SeverityError, `unknown key "unknownKey"`},
+				{4, SeverityError, "timeoutSec must be greater than or equal to 0"},
+				{7, SeverityError, "either step command or step call must be specified"},
+				{9, SeverityError, "invalid triggerRule"},
+			},
+		},
+		{
+			name: "Warnings",
+			file: path.Join(testdataDir, "lint_warnings.yaml"),
+			expected: []diagnostic{
+				{8, SeverityWarning, `function "unused" is never called`},
+				{13, SeverityWarning, `undefined variable "UNDEFINED_VAR"`},
+				{17, SeverityWarning, `depends on a missing step "missing"`},
+				{18, SeverityWarning, `step "3" is unreachable: it depends on a step that never runs`},
+				{24, SeverityWarning, `step "4" is unreachable: it is in a dependency cycle`},
+				{27, SeverityWarning, `step "5" is unreachable: it is in a dependency cycle`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := Lint("", tt.file)
+			require.Len(t, diags, len(tt.expected), "%v", diags)
+			for i, d := range diags {
+				require.Equal(t, tt.file, d.File)
+				require.Equal(t, tt.expected[i].line, d.Line, d.String())
+				require.Equal(t, tt.expected[i].severity, d.Severity, d.String())
+				require.Contains(t, d.Message, tt.expected[i].message)
+			}
+		})
+	}
+}
+
+func TestValidateYAMLAt(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := config.Get()
+	dagsDir := cfg.DAGs
+	cfg.DAGs = tmpDir
+	defer func() {
+		cfg.DAGs = dagsDir
+	}()
+
+	write := func(name, data string) string {
+		t.Helper()
+		file := path.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(path.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(data), 0600))
+		return file
+	}
+	write("team/base.yaml", `
+env:
+  - TEAM: team
+`)
+	write("team/common.yaml", `
+functions:
+  - name: greet
+    params: name
+    command: echo hello $name
+`)
+	spec := []byte(`
+include:
+  - common.yaml
+steps:
+  - name: "1"
+    call:
+      function: greet
+      args:
+        name: $TEAM
+`)
+	file := path.Join(tmpDir, "team", "etl.yaml")
+
+	t.Run("NestedInclude", func(t *testing.T) {
+		var loaded *DAG
+		err := ValidateYAMLAt(file, spec, func(d *DAG) error {
+			loaded = d
+			return nil
+		})
+		require.NoError(t, err)
+		require.Contains(t, loaded.Env, "TEAM=team")
+		require.Equal(t, "echo hello $TEAM", loaded.Steps[0].CmdWithArgs)
+	})
+	t.Run("IncludeNotFound", func(t *testing.T) {
+		err := ValidateYAMLAt(path.Join(tmpDir, "etl.yaml"), spec)
+		var verr *ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Contains(t, err.Error(), "common.yaml")
+	})
+}
+
+func TestLint_Pools(t *testing.T) {
+	cfg := config.Get()
+	pools := cfg.Pools
+	cfg.Pools = map[string]int{"warehouse": 2}
+	defer func() {
+		cfg.Pools = pools
+	}()
+
+	file := path.Join(testdataDir, "lint_pools.yaml")
+	diags := Lint("", file)
+	require.Len(t, diags, 1, "%v", diags)
+	require.Equal(t, 7, diags[0].Line)
+	require.Equal(t, SeverityError, diags[0].Severity)
+	require.Contains(t, diags[0].Message, `pool "unknown" is not defined in the config`)
+}
@@ -8,8 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 
+	"github.com/dagu-dev/dagu/internal/config"
 	"github.com/dagu-dev/dagu/internal/util"
 	"github.com/imdario/mergo"
 	"github.com/mitchellh/mapstructure"
@@ -20,8 +22,19 @@ import (
 var (
 	errConfigFileRequired = errors.New("config file was not specified")
 	errReadFile           = errors.New("failed to read file")
+	errBaseConfigSteps    = errors.New("base configuration must not define steps")
 )
 
+// BaseConfigFile is the name of the base configuration file of a directory
+// in the DAGs directory. It applies to the DAGs in the directory and its
+// subdirectories, and it is not a DAG itself.
+const BaseConfigFile = "base.yaml"
+
+// IsBaseConfig returns true if the file is a base configuration file of a directory.
+func IsBaseConfig(file string) bool {
+	return filepath.Base(file) == BaseConfigFile
+}
+
 // Load loads config from file.
 func Load(base, dag, params string) (*DAG, error) {
 	return loadDAG(dag, buildOpts{
@@ -32,6 +45,20 @@ func Load(base, dag, params string) (*DAG, error) {
 	})
 }
 
+// LoadForRun loads config from file to run the DAG. Unlike Load, the
+// values of the secrets are resolved and set as environment variables.
+// The secrets that refer to the secret store are read from secrets.
+func LoadForRun(base, dag, params string, secrets SecretStore) (*DAG, error) {
+	return loadDAG(dag, buildOpts{
+		base:           base,
+		parameters:     params,
+		metadataOnly:   false,
+		noEval:         false,
+		resolveSecrets: true,
+		secretStore:    secrets,
+	})
+}
+
 // LoadWithoutEval loads config from file without evaluating env variables.
 func LoadWithoutEval(dag string) (*DAG, error) {
 	return loadDAG(dag, buildOpts{
@@ -55,13 +82,56 @@ func LoadYAML(data []byte) (*DAG, error) {
 		return nil, err
 	}
 
-	def, err := decode(raw)
+	opts := buildOpts{metadataOnly: false, noEval: true}
+	def, err := decodeDefinition(raw, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	b := &builder{opts: buildOpts{metadataOnly: false, noEval: true}}
-	return b.build(def, nil)
+	b := &builder{opts: opts}
+	if len(def.Include) == 0 {
+		return b.build(def, nil)
+	}
+
+	// The included files are resolved against the DAGs directory.
+	dst := &DAG{}
+	if err := loadIncludes(dst, def, "", opts, nil); err != nil {
+		return nil, err
+	}
+	d, err := b.build(def, dst.Env)
+	if err != nil {
+		return nil, err
+	}
+	if err := merge(dst, d); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// LoadSteps loads steps from a YAML or JSON list of step definitions.
+// It is used to build the steps generated by a step at runtime.
+// The variables are set to each of the steps.
+func LoadSteps(data []byte, variables []string) ([]Step, error) {
+	var raw []any
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var defs []*stepDef
+	md, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      &defs,
+		TagName:     "",
+	})
+	if err := md.Decode(raw); err != nil {
+		return nil, err
+	}
+
+	sb := &stepBuilder{}
+	return sb.buildSteps(variables, defs, nil)
 }
 
 // loadBaseConfig loads the global configuration from the given file.
@@ -79,7 +149,7 @@ func loadBaseConfig(file string, opts buildOpts) (*DAG, error) {
 	}
 
 	// Decode the raw data into a config definition.
-	def, err := decode(raw)
+	def, err := decodeDefinition(raw, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +172,34 @@ func loadDAG(dag string, opts buildOpts) (*DAG, error) {
 		return nil, err
 	}
 
+	// Load the raw data from the file.
+	raw, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadDAGData(file, raw, opts)
+}
+
+// loadYAMLAt loads the DAG from the YAML data as if it were the content of
+// the given file. The included files and the base configurations of the
+// directories are resolved against the location of the file.
+func loadYAMLAt(file string, data []byte, opts buildOpts) (*DAG, error) {
+	file, err := prepareFilepath(file)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := unmarshalData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadDAGData(file, raw, opts)
+}
+
+// loadDAGData loads the DAG from the raw data of the given file.
+func loadDAGData(file string, raw map[string]any, opts buildOpts) (*DAG, error) {
 	// Load the base configuration unless only the metadata is required.
 	// If only the metadata is required, the base configuration is not loaded
 	// and the DAG is created with the default values.
@@ -110,18 +208,26 @@ func loadDAG(dag string, opts buildOpts) (*DAG, error) {
 		return nil, err
 	}
 
-	// Load the raw data from the file.
-	raw, err := readFile(file)
-	if err != nil {
+	// Layer the base configurations of the directories on top of it.
+	// They are loaded for the metadata as well so that the values are the same.
+	if err := loadDirBaseConfigs(dst, file, opts); err != nil {
 		return nil, err
 	}
 
 	// Decode the raw data into a config definition.
-	def, err := decode(raw)
+	def, err := decodeDefinition(raw, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	// Merge the included files into the base configuration.
+	// The DAG configuration overrides the included files.
+	if !opts.metadataOnly {
+		if err := loadIncludes(dst, def, file, opts, []string{file}); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build the DAG from the config definition.
 	b := builder{opts: opts}
 	c, err := b.build(def, dst.Env)
@@ -139,6 +245,11 @@ func loadDAG(dag string, opts buildOpts) (*DAG, error) {
 	// Set the absolute path to the file.
 	dst.Location = file
 
+	// The DAGs in a folder of the DAGs directory are grouped by the folder.
+	if dst.Group == "" {
+		dst.Group = folderGroup(file)
+	}
+
 	// Set the default values for the DAG.
 	if !opts.metadataOnly {
 		dst.setup()
@@ -162,6 +273,105 @@ func prepareFilepath(file string) (string, error) {
 	return filepath.Abs(file)
 }
 
+// loadDirBaseConfigs merges the base configuration files of the directories
+// from the DAGs directory down to the directory of the DAG file into the
+// destination DAG. A configuration in a deeper directory overrides the ones
+// above it. Nothing is loaded if the DAG file is outside the DAGs directory.
+func loadDirBaseConfigs(dst *DAG, file string, opts buildOpts) error {
+	rel, ok := dagsRelPath(file)
+	if !ok {
+		return nil
+	}
+
+	dir := dagsDir()
+	dirs := []string{dir}
+	for _, name := range strings.Split(filepath.Dir(rel), string(filepath.Separator)) {
+		if name != "." {
+			dir = filepath.Join(dir, name)
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		base := filepath.Join(dir, BaseConfigFile)
+		if base == file || !util.FileExists(base) {
+			continue
+		}
+		raw, err := readFile(base)
+		if err != nil {
+			return err
+		}
+		def, err := decodeDefinition(raw, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", base, err)
+		}
+		if len(def.Steps) > 0 {
+			return fmt.Errorf("%w: %s", errBaseConfigSteps, base)
+		}
+		if !opts.metadataOnly {
+			if err := loadIncludes(dst, def, base, opts, []string{base}); err != nil {
+				return err
+			}
+		}
+		b := &builder{opts: opts}
+		d, err := b.build(def, dst.Env)
+		if err != nil {
+			return fmt.Errorf("%s: %w", base, err)
+		}
+		if err := merge(dst, d); err != nil {
+			return fmt.Errorf("%s: %w", base, err)
+		}
+	}
+	return nil
+}
+
+// dagsDir returns the absolute path to the DAGs directory.
+func dagsDir() string {
+	dir := config.Get().DAGs
+	if dir == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// dagsRelPath returns the path of the file relative to the DAGs directory.
+// It returns false if the file is outside the DAGs directory.
+func dagsRelPath(file string) (string, bool) {
+	dir := dagsDir()
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// RelPath returns the path of the DAG file relative to the DAGs directory
+// with slashes, e.g. "team/etl.yaml". It is used as the ID of the DAG.
+// It returns the name of the file if the file is outside the DAGs directory.
+func RelPath(file string) string {
+	if rel, ok := dagsRelPath(file); ok {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(file)
+}
+
+// folderGroup returns the folder of the DAG file in the DAGs directory,
+// e.g. "team" for "team/etl.yaml". It is empty for the files in the top
+// level of the DAGs directory and the files outside of it.
+func folderGroup(file string) string {
+	rel, ok := dagsRelPath(file)
+	if !ok || filepath.Dir(rel) == "." {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Dir(rel))
+}
+
 // loadBaseConfigIfRequired loads the base config if needed, based on the given options.
 func loadBaseConfigIfRequired(baseConfig, file string, opts buildOpts) (*DAG, error) {
 	if !opts.metadataOnly && baseConfig != "" {
@@ -234,6 +444,22 @@ func decode(cm map[string]interface{}) (*definition, error) {
 	return c, err
 }
 
+// decodeDefinition decodes the configuration map into a config definition
+// with the references to the secret store. The references are replaced with
+// the environment variables of the secrets when the values are evaluated.
+func decodeDefinition(cm map[string]any, opts buildOpts) (*definition, error) {
+	var refs []string
+	findSecretRefs(cm, !opts.noEval, &refs)
+	slices.Sort(refs)
+
+	def, err := decode(cm)
+	if err != nil {
+		return nil, err
+	}
+	def.secretRefs = refs
+	return def, nil
+}
+
 // merge merges the source DAG into the destination DAG.
 func merge(dst, src *DAG) error {
 	return mergo.Merge(dst, src, mergo.WithOverride,
@@ -1,6 +1,7 @@
 package dag
 
 import (
+	"os"
 	"path"
 	"testing"
 	"time"
@@ -110,3 +111,365 @@ steps:
 		require.Error(t, err)
 	})
 }
+
+func Test_LoadParamSchema(t *testing.T) {
+	file := path.Join(testdataDir, "params.yaml")
+	tests := []struct {
+		name          string
+		params        string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "defaults",
+			params:   "COUNT=1",
+			expected: []string{`ENV="dev"`, `COUNT="1"`, `DRY_RUN="false"`},
+		},
+		{
+			name:     "override",
+			params:   `ENV=prod COUNT=2 DRY_RUN=true DATE="2024-01-31"`,
+			expected: []string{`ENV="prod"`, `COUNT="2"`, `DRY_RUN="true"`, `DATE="2024-01-31"`},
+		},
+		{
+			name:          "[Invalid] missing required param",
+			params:        "ENV=prod",
+			expectedError: "missing required param: COUNT",
+		},
+		{
+			name:          "[Invalid] int",
+			params:        "COUNT=abc",
+			expectedError: `invalid value for param COUNT: "abc" is not an int`,
+		},
+		{
+			name:          "[Invalid] enum",
+			params:        "COUNT=1 ENV=staging",
+			expectedError: `invalid value for param ENV: "staging" is not one of [dev prod]`,
+		},
+		{
+			name:          "[Invalid] date",
+			params:        "COUNT=1 DATE=2024-13-01",
+			expectedError: "invalid value for param DATE",
+		},
+		{
+			name:          "[Invalid] unknown param",
+			params:        "COUNT=1 FOO=bar",
+			expectedError: "unknown param: FOO",
+		},
+		{
+			name:          "[Invalid] positional param",
+			params:        "1",
+			expectedError: "param must be passed as NAME=value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Load("", file, tt.params)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d.Params)
+		})
+	}
+	t.Run("without eval", func(t *testing.T) {
+		d, err := LoadWithoutEval(file)
+		require.NoError(t, err)
+		require.Len(t, d.ParamSchema, 4)
+		require.Equal(t, `ENV="dev" DRY_RUN="false"`, d.DefaultParams)
+	})
+}
+
+func Test_LoadSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretFile := path.Join(tmpDir, "token")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0600))
+	t.Setenv("TEST_SECRET_SOURCE", "env-secret")
+
+	file := path.Join(tmpDir, "secrets.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+env:
+  - PLAIN: plain
+  - AUTH: Bearer ${TOKEN}
+secrets:
+  - name: PASSWORD
+    env: TEST_SECRET_SOURCE
+  - name: TOKEN
+    file: `+secretFile+`
+steps:
+  - name: "1"
+    command: echo $PASSWORD
+`), 0600))
+
+	d, err := LoadForRun("", file, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"env-secret", "file-secret"}, d.SecretValues())
+	require.Equal(t, "env-secret", os.Getenv("PASSWORD"))
+	require.Equal(t, "file-secret", os.Getenv("TOKEN"))
+	require.Equal(t, []string{"PLAIN=plain"}, d.Steps[0].Variables)
+
+	t.Run("secret store", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`
+steps:
+  - name: "1"
+    command: echo ${secret:DB_PASSWORD}
+`), 0600))
+		d, err := LoadForRun("", file, "", testSecretStore{"DB_PASSWORD": "store-secret"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"store-secret"}, d.SecretValues())
+		require.Equal(t, "echo ${DB_PASSWORD}", d.Steps[0].CmdWithArgs)
+		require.Equal(t, "store-secret", os.Getenv("DB_PASSWORD"))
+	})
+	t.Run("not resolved by Load", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`
+secrets:
+  - name: UNRESOLVED_PASSWORD
+    env: TEST_SECRET_SOURCE
+steps:
+  - name: "1"
+    command: "true"
+`), 0600))
+		d, err := Load("", file, "")
+		require.NoError(t, err)
+		require.Empty(t, d.SecretValues())
+		_, ok := os.LookupEnv("UNRESOLVED_PASSWORD")
+		require.False(t, ok)
+	})
+	t.Run("[Invalid] not found", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`
+secrets:
+  - name: PASSWORD
+    env: TEST_SECRET_NOT_EXIST
+steps:
+  - name: "1"
+    command: "true"
+`), 0600))
+		_, err := LoadForRun("", file, "", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "secret value not found")
+	})
+}
+
+type testSecretStore map[string]string
+
+func (s testSecretStore) Get(name string) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+	return "", errSecretNotFound
+}
+
+func Test_LoadIncludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		file := path.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(path.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(data), 0600))
+		return file
+	}
+	write("shared/env.yaml", `
+env:
+  - SHARED: shared
+  - REGION: us
+`)
+	write("shared/common.yaml", `
+include:
+  - env.yaml
+smtp:
+  host: smtp.example.com
+  port: "25"
+handlerOn:
+  failure:
+    command: echo failed
+functions:
+  - name: greet
+    params: name
+    command: echo hello $name
+`)
+
+	t.Run("Merge", func(t *testing.T) {
+		file := write("dag.yaml", `
+include:
+  - shared/common.yaml
+env:
+  - REGION: eu
+steps:
+  - name: "1"
+    call:
+      function: greet
+      args:
+        name: world
+`)
+		d, err := Load("", file, "")
+		require.NoError(t, err)
+		require.Equal(t, "dag", d.Name)
+		require.Contains(t, d.Env, "REGION=eu")
+		require.Contains(t, d.Env, "SHARED=shared")
+		require.NotContains(t, d.Env, "REGION=us")
+		require.Equal(t, "smtp.example.com", d.Smtp.Host)
+		require.NotNil(t, d.HandlerOn.Failure)
+		require.Equal(t, "echo hello world", d.Steps[0].CmdWithArgs)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		file := write("not_found.yaml", `
+include:
+  - shared/unknown.yaml
+steps:
+  - name: "1"
+    command: "true"
+`)
+		_, err := Load("", file, "")
+		require.ErrorIs(t, err, errIncludeNotFound)
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		write("shared/a.yaml", "include:\n  - b.yaml\n")
+		write("shared/b.yaml", "include:\n  - a.yaml\n")
+		file := write("cycle.yaml", `
+include:
+  - shared/a.yaml
+steps:
+  - name: "1"
+    command: "true"
+`)
+		_, err := Load("", file, "")
+		require.ErrorIs(t, err, errIncludeCycle)
+	})
+	t.Run("ErrorInFragment", func(t *testing.T) {
+		invalid := write("shared/invalid.yaml", "unknownField: 1\n")
+		file := write("invalid.yaml", `
+include:
+  - shared/invalid.yaml
+steps:
+  - name: "1"
+    command: "true"
+`)
+		_, err := Load("", file, "")
+		require.ErrorContains(t, err, invalid)
+	})
+	t.Run("Steps", func(t *testing.T) {
+		write("shared/steps.yaml", "steps:\n  - name: a\n    command: \"true\"\n")
+		file := write("steps.yaml", `
+include:
+  - shared/steps.yaml
+steps:
+  - name: "1"
+    command: "true"
+`)
+		_, err := Load("", file, "")
+		require.ErrorIs(t, err, errIncludeSteps)
+	})
+}
+
+func Test_LoadDirBaseConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := config.Get()
+	dagsDir := cfg.DAGs
+	cfg.DAGs = tmpDir
+	defer func() {
+		cfg.DAGs = dagsDir
+	}()
+
+	write := func(name, data string) string {
+		t.Helper()
+		file := path.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(path.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(data), 0600))
+		return file
+	}
+	write("base.yaml", `
+logDir: /tmp/logs
+env:
+  - TEAM: none
+  - ROOT: root
+`)
+	write("team/base.yaml", `
+group: team
+env:
+  - TEAM: team
+smtp:
+  host: smtp.team.example.com
+  port: "25"
+handlerOn:
+  failure:
+    command: echo failed
+`)
+	write("team/sub/base.yaml", `
+logDir: /tmp/team-logs
+`)
+	file := write("team/sub/dag.yaml", `
+steps:
+  - name: "1"
+    command: "true"
+`)
+
+	t.Run("Load", func(t *testing.T) {
+		d, err := Load("", file, "")
+		require.NoError(t, err)
+		require.Equal(t, "dag", d.Name)
+		require.Equal(t, "team", d.Group)
+		require.Equal(t, "/tmp/team-logs", d.LogDir)
+		require.Contains(t, d.Env, "TEAM=team")
+		require.Contains(t, d.Env, "ROOT=root")
+		require.NotContains(t, d.Env, "TEAM=none")
+		require.Equal(t, "smtp.team.example.com", d.Smtp.Host)
+		require.NotNil(t, d.HandlerOn.Failure)
+	})
+	t.Run("LoadMetadata", func(t *testing.T) {
+		d, err := LoadMetadata(file)
+		require.NoError(t, err)
+		require.Equal(t, "team", d.Group)
+		require.Contains(t, d.Env, "TEAM=team")
+	})
+	t.Run("OutsideDAGsDir", func(t *testing.T) {
+		d, err := Load("", path.Join(testdataDir, "default.yaml"), "")
+		require.NoError(t, err)
+		require.NotContains(t, d.Env, "ROOT=root")
+	})
+	t.Run("Steps", func(t *testing.T) {
+		write("invalid/base.yaml", "steps:\n  - name: a\n    command: \"true\"\n")
+		_, err := Load("", write("invalid/dag.yaml", "steps:\n  - name: b\n    command: \"true\"\n"), "")
+		require.ErrorIs(t, err, errBaseConfigSteps)
+	})
+	require.True(t, IsBaseConfig(path.Join(tmpDir, "team", "base.yaml")))
+}
+
+func Test_LoadSteps(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		steps, err := LoadSteps([]byte(`
+- name: partition-1
+  command: process.sh 1
+- name: partition-2
+  command: process.sh 2
+  depends: [partition-1]
+`), []string{"FOO=BAR"})
+		require.NoError(t, err)
+		require.Len(t, steps, 2)
+		require.Equal(t, "partition-1", steps[0].Name)
+		require.Equal(t, "process.sh", steps[0].Command)
+		require.Equal(t, []string{"1"}, steps[0].Args)
+		require.Equal(t, []string{"FOO=BAR"}, steps[0].Variables)
+		require.Equal(t, []string{"partition-1"}, steps[1].Depends)
+	})
+	t.Run("JSON", func(t *testing.T) {
+		steps, err := LoadSteps([]byte(`[{"name": "a", "command": "echo $P", "matrix": {"P": [1, 2]}}]`), nil)
+		require.NoError(t, err)
+		require.Len(t, steps, 2)
+		require.Equal(t, "a[P=1]", steps[0].Name)
+		require.Equal(t, "a[P=2]", steps[1].Name)
+	})
+	t.Run("Empty", func(t *testing.T) {
+		steps, err := LoadSteps([]byte(""), nil)
+		require.NoError(t, err)
+		require.Empty(t, steps)
+	})
+	t.Run("[Invalid] unknown field", func(t *testing.T) {
+		_, err := LoadSteps([]byte(`[{"name": "a", "command": "true", "unknown": 1}]`), nil)
+		require.Error(t, err)
+	})
+	t.Run("[Invalid] not a list", func(t *testing.T) {
+		_, err := LoadSteps([]byte(`name: a`), nil)
+		require.Error(t, err)
+	})
+}
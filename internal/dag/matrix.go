@@ -0,0 +1,172 @@
+package dag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MatrixVar is a variable to expand a step into multiple steps.
+// If Ref is set, Values are resolved at runtime from the JSON array
+// that Ref expands to, i.e. the output of an upstream step.
+type MatrixVar struct {
+	Name   string   // Name is the name of the variable.
+	Values []string // Values contains the values of the variable.
+	Ref    string   // Ref is a reference to a JSON array such as "$ITEMS" or "${steps.list.outputs.items}".
+}
+
+var (
+	errMatrixValueMustBeArrayOrString = errors.New("matrix value must be an array or a string")
+	errMatrixRefIsNotJSONArray        = errors.New("matrix reference must be a JSON array")
+	errMatrixValuesEmpty              = errors.New("matrix values must not be empty")
+)
+
+// isDynamicMatrix returns true if any of the variables needs to be resolved at runtime.
+func isDynamicMatrix(vars []MatrixVar) bool {
+	return slices.ContainsFunc(vars, func(v MatrixVar) bool { return v.Ref != "" })
+}
+
+// ResolveMatrix resolves the variables that refer to a JSON array.
+// The references are expanded with the given function.
+func ResolveMatrix(vars []MatrixVar, expand func(string) (string, error)) ([]MatrixVar, error) {
+	var ret []MatrixVar
+	for _, v := range vars {
+		if v.Ref == "" {
+			ret = append(ret, v)
+			continue
+		}
+		value, err := expand(v.Ref)
+		if err != nil {
+			return nil, err
+		}
+		var items []any
+		if err := json.Unmarshal([]byte(value), &items); err != nil {
+			return nil, fmt.Errorf("%w: %s=%q", errMatrixRefIsNotJSONArray, v.Name, value)
+		}
+		resolved := MatrixVar{Name: v.Name, Values: []string{}}
+		for _, item := range items {
+			resolved.Values = append(resolved.Values, matrixValue(item))
+		}
+		ret = append(ret, resolved)
+	}
+	return ret, nil
+}
+
+// ExpandMatrix expands the step into a step for each combination of the
+// values of the variables. The name of each step is the name of the step
+// followed by the values, e.g. "process[REGION=us-east-1]". The values are
+// set as the variables of the step and substituted in the command.
+func ExpandMatrix(step Step, vars []MatrixVar) []Step {
+	var ret []Step
+	for _, combination := range matrixCombinations(vars) {
+		expanded := step
+		expanded.Matrix = nil
+		expanded.Variables = slices.Clone(step.Variables)
+		var (
+			names  []string
+			values = map[string]string{}
+		)
+		for i, v := range vars {
+			names = append(names, fmt.Sprintf("%s=%s", v.Name, combination[i]))
+			values[v.Name] = combination[i]
+			expanded.Variables = append(expanded.Variables,
+				fmt.Sprintf("%s=%s", v.Name, combination[i]))
+		}
+		expanded.Name = fmt.Sprintf("%s[%s]", step.Name, strings.Join(names, ","))
+		substituteMatrixValues(&expanded, values)
+		ret = append(ret, expanded)
+	}
+	return ret
+}
+
+// matrixCombinations returns the cartesian product of the values.
+func matrixCombinations(vars []MatrixVar) [][]string {
+	ret := [][]string{{}}
+	for _, v := range vars {
+		var next [][]string
+		for _, c := range ret {
+			for _, value := range v.Values {
+				next = append(next, append(slices.Clone(c), value))
+			}
+		}
+		ret = next
+	}
+	return ret
+}
+
+// substituteMatrixValues replaces the references to the matrix variables
+// in the command of the step. Other references are kept as they are so
+// that they can be evaluated when the step runs.
+func substituteMatrixValues(step *Step, values map[string]string) {
+	expand := func(s string) string {
+		for name, value := range values {
+			re := regexp.MustCompile(`\$(\{` + regexp.QuoteMeta(name) + `\}|` + regexp.QuoteMeta(name) + `\b)`)
+			s = re.ReplaceAllLiteralString(s, value)
+		}
+		return s
+	}
+	step.CmdWithArgs = expand(step.CmdWithArgs)
+	step.Command = expand(step.Command)
+	step.Args = slices.Clone(step.Args)
+	for i, arg := range step.Args {
+		step.Args[i] = expand(arg)
+	}
+	step.Dir = expand(step.Dir)
+	step.Stdout = expand(step.Stdout)
+	step.Stderr = expand(step.Stderr)
+	if step.SubWorkflow != nil {
+		step.SubWorkflow = &SubWorkflow{
+			Name:   step.SubWorkflow.Name,
+			Params: expand(step.SubWorkflow.Params),
+		}
+	}
+}
+
+// matrixValue converts a value of a JSON array or a YAML list to a string.
+func matrixValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64, int, bool:
+		return fmt.Sprintf("%v", v)
+	default:
+		js, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(js)
+	}
+}
+
+// buildMatrix builds the matrix variables from the definition.
+// The keys are sorted so that the order of the expanded steps is stable.
+func buildMatrix(def map[string]any) ([]MatrixVar, error) {
+	var keys []string
+	for k := range def {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var ret []MatrixVar
+	for _, k := range keys {
+		switch val := def[k].(type) {
+		case string:
+			ret = append(ret, MatrixVar{Name: k, Ref: val})
+		case []any:
+			if len(val) == 0 {
+				return nil, fmt.Errorf("%w: %s", errMatrixValuesEmpty, k)
+			}
+			v := MatrixVar{Name: k, Values: []string{}}
+			for _, item := range val {
+				v.Values = append(v.Values, matrixValue(item))
+			}
+			ret = append(ret, v)
+		default:
+			return nil, fmt.Errorf("%w: %s", errMatrixValueMustBeArrayOrString, k)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,64 @@
+package dag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	step := Step{
+		Name:        "process",
+		CmdWithArgs: "process.sh ${REGION} $ENV $ENVIRONMENT",
+		Command:     "process.sh",
+		Args:        []string{"${REGION}", "$ENV", "$ENVIRONMENT"},
+		Variables:   []string{"FOO=BAR"},
+		Depends:     []string{"setup"},
+	}
+	steps := ExpandMatrix(step, []MatrixVar{
+		{Name: "ENV", Values: []string{"dev", "prod"}},
+		{Name: "REGION", Values: []string{"us", "eu"}},
+	})
+	require.Len(t, steps, 4)
+
+	var names []string
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	require.Equal(t, []string{
+		"process[ENV=dev,REGION=us]",
+		"process[ENV=dev,REGION=eu]",
+		"process[ENV=prod,REGION=us]",
+		"process[ENV=prod,REGION=eu]",
+	}, names)
+
+	require.Equal(t, "process.sh eu prod $ENVIRONMENT", steps[3].CmdWithArgs)
+	require.Equal(t, []string{"eu", "prod", "$ENVIRONMENT"}, steps[3].Args)
+	require.Equal(t, []string{"FOO=BAR", "ENV=prod", "REGION=eu"}, steps[3].Variables)
+	require.Equal(t, []string{"setup"}, steps[3].Depends)
+
+	// the original step is not modified
+	require.Equal(t, []string{"FOO=BAR"}, step.Variables)
+	require.Equal(t, []string{"${REGION}", "$ENV", "$ENVIRONMENT"}, step.Args)
+}
+
+func TestResolveMatrix(t *testing.T) {
+	step := Step{OutputVariables: &SyncMap{}}
+	step.OutputVariables.Store("TEST_MATRIX_ITEMS", `TEST_MATRIX_ITEMS=["a", 1, {"k": "v"}]`)
+	step.OutputVariables.Store("TEST_MATRIX_INVALID", "TEST_MATRIX_INVALID=a,b")
+	expand := func(ref string) (string, error) {
+		return step.ExpandEnv(ref), nil
+	}
+	vars, err := ResolveMatrix([]MatrixVar{
+		{Name: "ITEM", Ref: "$TEST_MATRIX_ITEMS"},
+		{Name: "ENV", Values: []string{"dev"}},
+	}, expand)
+	require.NoError(t, err)
+	require.Equal(t, []MatrixVar{
+		{Name: "ITEM", Values: []string{"a", "1", `{"k":"v"}`}},
+		{Name: "ENV", Values: []string{"dev"}},
+	}, vars)
+
+	_, err = ResolveMatrix([]MatrixVar{{Name: "ITEM", Ref: "$TEST_MATRIX_INVALID"}}, expand)
+	require.ErrorIs(t, err, errMatrixRefIsNotJSONArray)
+}
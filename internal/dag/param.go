@@ -0,0 +1,196 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// ParamType is the type of the value of a parameter.
+type ParamType string
+
+const (
+	ParamTypeString ParamType = "string"
+	ParamTypeInt    ParamType = "int"
+	ParamTypeBool   ParamType = "bool"
+	ParamTypeEnum   ParamType = "enum"
+	ParamTypeDate   ParamType = "date"
+)
+
+// paramDateLayout is the layout of the value of a date parameter.
+const paramDateLayout = "2006-01-02"
+
+// IsValid returns true if the parameter type is known.
+func (t ParamType) IsValid() bool {
+	switch t {
+	case ParamTypeString, ParamTypeInt, ParamTypeBool, ParamTypeEnum, ParamTypeDate:
+		return true
+	}
+	return false
+}
+
+// Param is a named parameter of the DAG defined in the params schema.
+type Param struct {
+	Name        string    `json:"Name"`
+	Type        ParamType `json:"Type"`
+	Default     string    `json:"Default,omitempty"`
+	Required    bool      `json:"Required,omitempty"`
+	Description string    `json:"Description,omitempty"`
+	Values      []string  `json:"Values,omitempty"` // Values are the allowed values of an enum parameter.
+}
+
+// paramDef is the definition of a parameter in the params schema.
+type paramDef struct {
+	Name        string
+	Type        string
+	Default     any
+	Required    bool
+	Description string
+	Values      []any
+}
+
+var (
+	errParamsMustBeStringOrArray = errors.New("params must be a string or an array of parameter definitions")
+	errParamNameRequired         = errors.New("param name must be specified")
+	errDuplicateParam            = errors.New("duplicate param")
+	errInvalidParamType          = errors.New("invalid param type")
+	errParamValuesRequired       = errors.New("enum param requires values")
+	errInvalidParamValue         = errors.New("invalid value for param")
+	errParamRequired             = errors.New("missing required param")
+	errParamMustBeNamed          = errors.New("param must be passed as NAME=value")
+	errUnknownParam              = errors.New("unknown param")
+)
+
+// buildParamSchema builds the params schema from the definition.
+// The type of a parameter defaults to string.
+func buildParamSchema(defs []any) ([]Param, error) {
+	var ret []Param
+	for _, v := range defs {
+		def := &paramDef{}
+		md, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			ErrorUnused: true,
+			Result:      def,
+		})
+		if err := md.Decode(v); err != nil {
+			return nil, err
+		}
+		if def.Name == "" {
+			return nil, errParamNameRequired
+		}
+		if slices.ContainsFunc(ret, func(p Param) bool { return p.Name == def.Name }) {
+			return nil, fmt.Errorf("%w: %s", errDuplicateParam, def.Name)
+		}
+
+		param := Param{
+			Name:        def.Name,
+			Type:        ParamType(def.Type),
+			Required:    def.Required,
+			Description: def.Description,
+		}
+		if param.Type == "" {
+			param.Type = ParamTypeString
+		}
+		if !param.Type.IsValid() {
+			return nil, fmt.Errorf("%w: %s: %s", errInvalidParamType, def.Name, def.Type)
+		}
+		if def.Default != nil {
+			param.Default = matrixValue(def.Default)
+		}
+		for _, value := range def.Values {
+			param.Values = append(param.Values, matrixValue(value))
+		}
+		if param.Type == ParamTypeEnum && len(param.Values) == 0 {
+			return nil, fmt.Errorf("%w: %s", errParamValuesRequired, def.Name)
+		}
+		// Defaults that are evaluated at runtime are validated when the DAG is loaded for execution.
+		if param.Default != "" && !strings.ContainsAny(param.Default, "$`") {
+			if err := param.validate(param.Default); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, param)
+	}
+	return ret, nil
+}
+
+// validate returns an error if the value is not valid for the type of the parameter.
+func (p Param) validate(value string) error {
+	var err error
+	switch p.Type {
+	case ParamTypeInt:
+		if _, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("%q is not an int", value)
+		}
+	case ParamTypeBool:
+		if _, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("%q is not a bool", value)
+		}
+	case ParamTypeDate:
+		if _, err = time.Parse(paramDateLayout, value); err != nil {
+			err = fmt.Errorf("%q is not a date in the format YYYY-MM-DD", value)
+		}
+	case ParamTypeEnum:
+		if !slices.Contains(p.Values, value) {
+			err = fmt.Errorf("%q is not one of %v", value, p.Values)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w %s: %s", errInvalidParamValue, p.Name, err)
+	}
+	return nil
+}
+
+// defaultParams returns the default parameters of the params schema
+// in the same format as the free-form params of the DAG.
+func defaultParams(schema []Param) string {
+	var ret []string
+	for _, p := range schema {
+		if p.Default == "" {
+			continue
+		}
+		ret = append(ret, stringifyParam(paramPair{name: p.Name, value: p.Default}))
+	}
+	return strings.Join(ret, " ")
+}
+
+// resolveParams merges the given parameters with the defaults of the
+// params schema. All of the given parameters must be named and defined in
+// the schema, and the required parameters must have a value.
+func resolveParams(schema []Param, input string) (string, error) {
+	given, err := parseParams(input, false)
+	if err != nil {
+		return "", err
+	}
+
+	values := map[string]string{}
+	for _, p := range given {
+		if p.name == "" {
+			return "", fmt.Errorf("%w: %q", errParamMustBeNamed, p.value)
+		}
+		if !slices.ContainsFunc(schema, func(s Param) bool { return s.Name == p.name }) {
+			return "", fmt.Errorf("%w: %s", errUnknownParam, p.name)
+		}
+		values[p.name] = p.value
+	}
+
+	var ret []string
+	for _, p := range schema {
+		value, ok := values[p.Name]
+		if !ok {
+			value = p.Default
+		}
+		if value == "" {
+			if p.Required {
+				return "", fmt.Errorf("%w: %s", errParamRequired, p.Name)
+			}
+			continue
+		}
+		ret = append(ret, stringifyParam(paramPair{name: p.Name, value: value}))
+	}
+	return strings.Join(ret, " "), nil
+}
@@ -0,0 +1,285 @@
+package dag
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dagu-dev/dagu/schemas"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaError is a violation of the JSON schema of the DAG files.
+type schemaError struct {
+	node    *yaml.Node // node is the node to report the violation at.
+	path    string     // path is the path to the node such as "steps[0].name".
+	unknown bool       // unknown is true if the violation is an unknown key.
+	message string
+}
+
+// schemaValidator validates a YAML document against the JSON schema of the
+// DAG files. It supports the subset of the JSON schema that is used by the
+// schema: type, properties, additionalProperties, required, items, enum,
+// minimum, minItems, pattern, oneOf, allOf and local $ref.
+type schemaValidator struct {
+	root map[string]any
+}
+
+var (
+	dagSchema     *schemaValidator
+	dagSchemaErr  error
+	dagSchemaOnce sync.Once
+)
+
+// loadDAGSchema returns the validator of the embedded schema of the DAG files.
+func loadDAGSchema() (*schemaValidator, error) {
+	dagSchemaOnce.Do(func() {
+		var root map[string]any
+		if err := json.Unmarshal(schemas.DAG, &root); err != nil {
+			dagSchemaErr = fmt.Errorf("invalid DAG schema: %w", err)
+			return
+		}
+		dagSchema = &schemaValidator{root: root}
+	})
+	return dagSchema, dagSchemaErr
+}
+
+// validate validates the node against the schema. The pos is the node to
+// report the violations of the node itself at, which is the key of the node
+// for the values of a mapping.
+// nolint // cognitive complexity
+func (v *schemaValidator) validate(node, pos *yaml.Node, schema map[string]any, path string) []schemaError {
+	node = resolveAlias(node)
+	schema = v.resolveRef(schema)
+
+	fail := func(format string, args ...any) []schemaError {
+		return []schemaError{{node: pos, path: path, message: fmt.Sprintf("%s: %s", displayPath(path), fmt.Sprintf(format, args...))}}
+	}
+
+	// A null value is the same as the key being omitted.
+	if nodeType(node) == "null" {
+		return nil
+	}
+
+	if typ, ok := schema["type"].(string); ok && !typeMatches(node, typ) {
+		return fail("expected %s, got %s", typ, nodeType(node))
+	}
+
+	if sub, ok := schema["allOf"].([]any); ok {
+		var errs []schemaError
+		for _, s := range sub {
+			errs = append(errs, v.validate(node, pos, s.(map[string]any), path)...)
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
+	// The branches of oneOf in the schema are disjoint, so the node is valid
+	// if it matches any of them.
+	if sub, ok := schema["oneOf"].([]any); ok {
+		var (
+			valid   bool
+			types   []string
+			matched [][]schemaError
+		)
+		for _, s := range sub {
+			branch := v.resolveRef(s.(map[string]any))
+			errs := v.validate(node, pos, branch, path)
+			if len(errs) == 0 {
+				valid = true
+				break
+			}
+			typ, _ := branch["type"].(string)
+			types = append(types, typ)
+			if typ != "" && typeMatches(node, typ) {
+				matched = append(matched, errs)
+			}
+		}
+		if !valid {
+			// Report the violations in the branch of the same type as the
+			// node since they are more specific.
+			if len(matched) == 1 {
+				return matched[0]
+			}
+			return fail("expected %s, got %s", strings.Join(types, " or "), nodeType(node))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return fmt.Sprint(e) == node.Value }) {
+			var values []string
+			for _, e := range enum {
+				values = append(values, fmt.Sprint(e))
+			}
+			return fail("%q is not one of %s", node.Value, strings.Join(values, ", "))
+		}
+	}
+
+	if minimum, ok := schema["minimum"].(float64); ok && node.Kind == yaml.ScalarNode {
+		if f, err := strconv.ParseFloat(node.Value, 64); err == nil && f < minimum {
+			return fail("must be greater than or equal to %v", minimum)
+		}
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok && node.Kind == yaml.ScalarNode {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(node.Value) {
+			return fail("%q does not match %s", node.Value, pattern)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if minItems, ok := schema["minItems"].(float64); ok && len(node.Content) < int(minItems) {
+			return fail("must have at least %d items", int(minItems))
+		}
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return nil
+		}
+		var errs []schemaError
+		for i, item := range node.Content {
+			errs = append(errs, v.validate(item, item, items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+
+	case yaml.MappingNode:
+		return v.validateMapping(node, pos, schema, path)
+
+	default:
+		return nil
+	}
+}
+
+// validateMapping validates the keys and the values of the mapping node.
+// The keys are matched case-insensitively like the loader does.
+func (v *schemaValidator) validateMapping(node, pos *yaml.Node, schema map[string]any, path string) []schemaError {
+	props, _ := schema["properties"].(map[string]any)
+	findProp := func(key string) map[string]any {
+		for name, s := range props {
+			if strings.EqualFold(name, key) {
+				return s.(map[string]any)
+			}
+		}
+		return nil
+	}
+
+	var errs []schemaError
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			// merge key
+			continue
+		}
+		keyPath := joinPath(path, key.Value)
+		if s := findProp(key.Value); s != nil {
+			errs = append(errs, v.validate(value, key, s, keyPath)...)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				errs = append(errs, schemaError{node: key, path: keyPath, unknown: true, message: fmt.Sprintf("unknown key %q", keyPath)})
+			}
+		case map[string]any:
+			errs = append(errs, v.validate(value, key, additional, keyPath)...)
+		}
+	}
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name := r.(string)
+			if lookupKey(node, name) == nil {
+				errs = append(errs, schemaError{node: pos, path: path, message: fmt.Sprintf("%s: %q is required", displayPath(path), name)})
+			}
+		}
+	}
+	return errs
+}
+
+// resolveRef returns the schema referred to by the $ref of the schema.
+// Only the references to the definitions in the same schema are supported.
+func (v *schemaValidator) resolveRef(schema map[string]any) map[string]any {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema
+	}
+	cur := any(v.root)
+	for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return map[string]any{}
+		}
+		cur = m[name]
+	}
+	ret, _ := cur.(map[string]any)
+	return ret
+}
+
+// typeMatches returns true if the node is of the JSON schema type.
+func typeMatches(node *yaml.Node, typ string) bool {
+	actual := nodeType(node)
+	return actual == typ || (typ == "number" && actual == "integer")
+}
+
+// nodeType returns the JSON schema type of the node.
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int":
+			return "integer"
+		case "!!float":
+			return "number"
+		case "!!bool":
+			return "boolean"
+		case "!!null":
+			return "null"
+		}
+		return "string"
+	}
+	return "unknown"
+}
+
+// resolveAlias returns the node that the alias node refers to.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// lookupKey returns the key node of the mapping node with the name.
+// The name is matched case-insensitively.
+func lookupKey(node *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, name) {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// joinPath returns the path to the key of the mapping at the path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath returns the path for the messages.
+func displayPath(path string) string {
+	if path == "" {
+		return "DAG"
+	}
+	return path
+}
@@ -0,0 +1,193 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Secret is a sensitive value that is resolved when the DAG is loaded to
+// run it. The value is set as an environment variable with the name of
+// the secret, but it is not stored in the variables of the steps and it is
+// masked in the logs of the steps.
+type Secret struct {
+	Name  string `json:"Name"`            // Name is the name of the environment variable.
+	Env   string `json:"Env,omitempty"`   // Env is the environment variable to read the value from.
+	File  string `json:"File,omitempty"`  // File is the file to read the value from.
+	Store string `json:"Store,omitempty"` // Store is the name of the secret in the secret store.
+	value string
+}
+
+// secretDef is the definition of a secret.
+type secretDef struct {
+	Name  string
+	Env   string
+	File  string
+	Store string
+}
+
+// SecretStore is the store to read the values of the secrets from.
+type SecretStore interface {
+	Get(name string) (string, error)
+}
+
+// secretRefRegex matches a reference to a secret in the secret store
+// such as "${secret:DB_PASSWORD}".
+var secretRefRegex = regexp.MustCompile(`\$\{secret:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var (
+	errSecretNameRequired   = errors.New("secret name must be specified")
+	errDuplicateSecret      = errors.New("duplicate secret")
+	errSecretSourceRequired = errors.New("secret must have exactly one of env, file or store")
+	errSecretNotFound       = errors.New("secret value not found")
+	errSecretStoreNotSet    = errors.New("secret store is not configured")
+)
+
+// Value returns the resolved value of the secret.
+// It is empty unless the DAG was loaded with LoadForRun.
+func (s Secret) Value() string {
+	return s.value
+}
+
+// resolve reads the value of the secret from its source.
+func (s *Secret) resolve(store SecretStore) error {
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return fmt.Errorf("%w: %s: environment variable %s is not set", errSecretNotFound, s.Name, s.Env)
+		}
+		s.value = value
+	case s.File != "":
+		data, err := os.ReadFile(os.ExpandEnv(s.File))
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", errSecretNotFound, s.Name, err)
+		}
+		s.value = strings.TrimRight(string(data), "\r\n")
+	case s.Store != "":
+		if store == nil {
+			return fmt.Errorf("%w: %s", errSecretStoreNotSet, s.Name)
+		}
+		value, err := store.Get(s.Store)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", errSecretNotFound, s.Name, err)
+		}
+		s.value = value
+	}
+	return nil
+}
+
+// buildSecrets builds the secrets from the definitions and the references
+// to the secret store. The values are resolved and set as environment
+// variables if resolve is true. The secrets that refer to the secret store
+// are read from the store.
+func buildSecrets(defs []*secretDef, refs []string, resolve bool, store SecretStore) ([]Secret, error) {
+	var ret []Secret
+	for _, def := range defs {
+		if def.Name == "" {
+			return nil, errSecretNameRequired
+		}
+		if slices.ContainsFunc(ret, func(s Secret) bool { return s.Name == def.Name }) {
+			return nil, fmt.Errorf("%w: %s", errDuplicateSecret, def.Name)
+		}
+		var sources int
+		for _, src := range []string{def.Env, def.File, def.Store} {
+			if src != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return nil, fmt.Errorf("%w: %s", errSecretSourceRequired, def.Name)
+		}
+		ret = append(ret, Secret{Name: def.Name, Env: def.Env, File: def.File, Store: def.Store})
+	}
+
+	// The references are the same as the secrets that refer to the store
+	// with the same name.
+	for _, name := range refs {
+		i := slices.IndexFunc(ret, func(s Secret) bool { return s.Name == name })
+		if i < 0 {
+			ret = append(ret, Secret{Name: name, Store: name})
+		} else if ret[i].Store != name {
+			return nil, fmt.Errorf("%w: %s", errDuplicateSecret, name)
+		}
+	}
+
+	if !resolve {
+		return ret, nil
+	}
+	for i := range ret {
+		secret := &ret[i]
+		if err := secret.resolve(store); err != nil {
+			return nil, err
+		}
+		if err := os.Setenv(secret.Name, secret.value); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// findSecretRefs finds the references to the secret store in the string
+// values of the configuration. If rewrite is true, the references are
+// replaced with the environment variables that the secrets are set to.
+func findSecretRefs(v any, rewrite bool, refs *[]string) any {
+	switch v := v.(type) {
+	case string:
+		for _, m := range secretRefRegex.FindAllStringSubmatch(v, -1) {
+			if !slices.Contains(*refs, m[1]) {
+				*refs = append(*refs, m[1])
+			}
+		}
+		if rewrite {
+			return secretRefRegex.ReplaceAllString(v, "$${$1}")
+		}
+		return v
+	case map[string]any:
+		for k, val := range v {
+			v[k] = findSecretRefs(val, rewrite, refs)
+		}
+	case map[any]any:
+		for k, val := range v {
+			v[k] = findSecretRefs(val, rewrite, refs)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = findSecretRefs(val, rewrite, refs)
+		}
+	}
+	return v
+}
+
+// SecretValues returns the resolved values of the secrets of the DAG.
+func (d *DAG) SecretValues() []string {
+	var ret []string
+	for _, s := range d.Secrets {
+		if s.value != "" {
+			ret = append(ret, s.value)
+		}
+	}
+	return ret
+}
+
+// excludeSecrets returns the variables without the ones that are secrets
+// or that contain the value of a secret.
+func excludeSecrets(variables []string, secrets []Secret) []string {
+	if len(secrets) == 0 {
+		return variables
+	}
+	var ret []string
+	for _, v := range variables {
+		name, value, _ := strings.Cut(v, "=")
+		if slices.ContainsFunc(secrets, func(s Secret) bool {
+			return s.Name == name || (s.value != "" && strings.Contains(value, s.value))
+		}) {
+			continue
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
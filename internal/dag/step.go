@@ -2,6 +2,7 @@ package dag
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"strings"
 	"time"
@@ -27,14 +28,59 @@ type ExecutorConfig struct {
 
 // RetryPolicy contains the retry policy for a step.
 type RetryPolicy struct {
-	Limit    int           // Limit is the number of retries allowed.
-	Interval time.Duration // Interval is the time to wait between retries.
+	Limit       int           // Limit is the number of retries allowed.
+	Interval    time.Duration // Interval is the time to wait between retries.
+	Backoff     float64       // Backoff is the multiplier applied to the interval after each retry.
+	MaxInterval time.Duration // MaxInterval is the upper bound of the interval. No limit if zero.
+	Jitter      time.Duration // Jitter is the upper bound of the random time added to the interval.
+	ExitCodes   []int         // ExitCodes limits retries to the given exit codes. Any error is retried if empty.
 }
 
 // RepeatPolicy contains the repeat policy for a step.
+// If Condition or ExitCode is set, the step repeats until the condition is met
+// or while the step exits with one of the exit codes, and then it finishes
+// so that the downstream steps can run.
 type RepeatPolicy struct {
-	Repeat   bool          // Repeat determines if the step should be repeated.
-	Interval time.Duration // Interval is the time to wait between repeats.
+	Repeat    bool          // Repeat determines if the step should be repeated.
+	Interval  time.Duration // Interval is the time to wait between repeats.
+	Condition *Condition    // Condition is evaluated after each run. The step repeats until it is met.
+	ExitCode  []int         // ExitCode contains the exit codes that make the step repeat.
+	Limit     int           // Limit is the maximum number of runs. No limit if zero.
+}
+
+// IsConditional returns true if the repeat stops by the condition or the exit code.
+func (r RepeatPolicy) IsConditional() bool {
+	return r.Condition != nil || len(r.ExitCode) > 0
+}
+
+// WaitFor makes a step wait until its preconditions are met
+// instead of skipping it.
+type WaitFor struct {
+	PollInterval time.Duration // PollInterval is the time to wait between evaluations of the preconditions.
+	Timeout      time.Duration // Timeout is the maximum time to wait. No limit if zero.
+}
+
+// TriggerRule determines when a step runs depending on the status of
+// the steps it depends on.
+type TriggerRule string
+
+const (
+	TriggerRuleAllSuccess TriggerRule = "all_success" // all dependencies succeeded (default)
+	TriggerRuleAllDone    TriggerRule = "all_done"    // all dependencies finished regardless of the status
+	TriggerRuleOneSuccess TriggerRule = "one_success" // at least one dependency succeeded
+	TriggerRuleOneFailed  TriggerRule = "one_failed"  // at least one dependency failed
+	TriggerRuleAllFailed  TriggerRule = "all_failed"  // all dependencies failed
+	TriggerRuleNoneFailed TriggerRule = "none_failed" // no dependency failed, i.e. succeeded or skipped
+)
+
+// IsValid returns true if the trigger rule is empty or one of the known rules.
+func (r TriggerRule) IsValid() bool {
+	switch r {
+	case "", TriggerRuleAllSuccess, TriggerRuleAllDone, TriggerRuleOneSuccess,
+		TriggerRuleOneFailed, TriggerRuleAllFailed, TriggerRuleNoneFailed:
+		return true
+	}
+	return false
 }
 
 // ContinueOn contains the conditions to continue on failure or skipped.
@@ -65,15 +111,33 @@ type Step struct {
 	Stderr          string         `json:"Stderr,omitempty"`          // Stderr is the file to store the standard error.
 	Output          string         `json:"Output,omitempty"`          // Output is the variable name to store the output.
 	Depends         []string       `json:"Depends,omitempty"`         // Depends contains the list of step names to depend on.
+	Matrix          []MatrixVar    `json:"Matrix,omitempty"`          // Matrix contains the variables to expand the step at runtime.
+	GenerateSteps   bool           `json:"GenerateSteps,omitempty"`   // GenerateSteps is the flag to add the steps printed by the step to the DAG.
+	GeneratedBy     string         `json:"GeneratedBy,omitempty"`     // GeneratedBy is the name of the step that generated the step at runtime.
+	TriggerRule     TriggerRule    `json:"TriggerRule,omitempty"`     // TriggerRule determines when the step runs depending on the status of the dependencies.
 	ContinueOn      ContinueOn     `json:"ContinueOn,omitempty"`      // ContinueOn contains the conditions to continue on failure or skipped.
 	RetryPolicy     *RetryPolicy   `json:"RetryPolicy,omitempty"`     // RetryPolicy contains the retry policy for the step.
 	RepeatPolicy    RepeatPolicy   `json:"RepeatPolicy,omitempty"`    // RepeatPolicy contains the repeat policy for the step.
 	MailOnError     bool           `json:"MailOnError,omitempty"`     // MailOnError is the flag to send mail on error.
 	Preconditions   []*Condition   `json:"Preconditions,omitempty"`   // Preconditions contains the conditions to be met before running the step.
+	WaitFor         *WaitFor       `json:"WaitFor,omitempty"`         // WaitFor makes the step wait until the preconditions are met.
 	SignalOnStop    string         `json:"SignalOnStop,omitempty"`    // SignalOnStop is the signal to send on stop.
+	Timeout         time.Duration  `json:"Timeout,omitempty"`         // Timeout is the maximum time the step is allowed to run.
+	Pool            string         `json:"Pool,omitempty"`            // Pool is the name of the resource pool to acquire a slot of before running.
 	SubWorkflow     *SubWorkflow   `json:"SubWorkflow,omitempty"`     // SubWorkflow contains the information about a sub DAG to be executed.
 }
 
+// ExpandEnv replaces ${var} or $var in the value with the output variables
+// of the steps that have run, or with the environment variables.
+func (s *Step) ExpandEnv(value string) string {
+	return os.Expand(value, func(name string) string {
+		if v, ok := s.OutputVariables.Variable(name); ok {
+			return v
+		}
+		return os.Getenv(name)
+	})
+}
+
 // setup sets the default values for the step.
 func (s *Step) setup(workDir string) {
 	// if the working directory is not set, use the directory of the DAG file.
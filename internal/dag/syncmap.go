@@ -2,6 +2,7 @@ package dag
 
 import (
 	"encoding/json"
+	"strings"
 	"sync"
 )
 
@@ -31,3 +32,17 @@ func (m *SyncMap) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Variable returns the value of the output variable that is stored as
+// "name=value".
+func (m *SyncMap) Variable(name string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	v, ok := m.Load(name)
+	if !ok {
+		return "", false
+	}
+	s, _ := v.(string)
+	return strings.TrimPrefix(s, name+"="), true
+}
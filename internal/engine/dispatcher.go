@@ -0,0 +1,278 @@
+package engine
+
+import (
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/dag"
+	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/model"
+	"github.com/dagu-dev/dagu/internal/sock"
+	"github.com/dagu-dev/dagu/internal/util"
+)
+
+// dispatchInterval is the interval to check the run queue.
+var dispatchInterval = time.Second
+
+// startCheckInterval is the interval to check if a run started by the
+// dispatcher is listening on the socket.
+var startCheckInterval = time.Millisecond * 100
+
+// Dispatcher starts the runs in the run queue. The runs with a higher
+// priority are started first, and the runs with the same priority are
+// started in the order they were enqueued. At most maxRunningDAGs runs
+// are running at the same time if the limit is positive.
+//
+// Both the server and the scheduler have a dispatcher, but only the one
+// that holds the lock file in the data directory starts the runs. The
+// queued runs are not started while neither of them is running.
+type Dispatcher struct {
+	engine         *engineImpl
+	queueStore     persistence.QueueStore
+	maxRunningDAGs int
+	lockFile       string
+	lock           *os.File
+	// started has the socket addresses of the runs started by the dispatcher
+	// that have not finished. A run is counted as running before it starts
+	// listening on the socket, and it stays in the queue until then.
+	started map[string]string
+	mu      sync.Mutex
+	stop    chan struct{}
+	// stopOnce closes the stop channel only once.
+	stopOnce sync.Once
+	// lockFailed is true while the lock is held by another process, so
+	// that the failure is logged only once.
+	lockFailed bool
+}
+
+func NewDispatcher(ds persistence.DataStoreFactory, cfg *config.Config) *Dispatcher {
+	return &Dispatcher{
+		engine: &engineImpl{
+			dataStoreFactory: ds,
+			executable:       cfg.Executable,
+			workDir:          cfg.WorkDir,
+		},
+		queueStore:     ds.NewQueueStore(),
+		maxRunningDAGs: cfg.MaxRunningDAGs,
+		lockFile:       path.Join(cfg.DataDir, "dispatcher.lock"),
+		started:        map[string]string{},
+		stop:           make(chan struct{}),
+	}
+}
+
+// Start starts checking the run queue in the background.
+func (d *Dispatcher) Start() {
+	go func() {
+		ticker := time.NewTicker(dispatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				util.LogErr("dispatching queued runs", d.dispatch())
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the run queue. The runs already started keep running.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.lock != nil {
+		_ = d.lock.Close()
+		d.lock = nil
+	}
+}
+
+// dispatch starts the queued runs while the number of running DAGs is
+// below the limit. A run is skipped and stays in the queue if its DAG is
+// running as many runs as it allows.
+func (d *Dispatcher) dispatch() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.acquireLock() {
+		return nil
+	}
+	runs, err := d.queueStore.List()
+	if err != nil || len(runs) == 0 {
+		return err
+	}
+	running, err := d.runningAddrs()
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if _, ok := d.started[run.RequestId]; ok {
+			// The run is starting.
+			continue
+		}
+		if d.maxRunningDAGs > 0 && len(running) >= d.maxRunningDAGs {
+			return nil
+		}
+		dg, err := dag.LoadMetadata(run.DAGFile)
+		if err != nil {
+			log.Printf("failed to load the queued DAG %s: %v", run.DAGFile, err)
+			util.LogErr("removing a queued run", d.queueStore.Remove(run.RequestId))
+			continue
+		}
+		if countMatches(running, dg.SockAddrPattern()) >= dg.RunLimit() {
+			continue
+		}
+		addr := dg.SockAddr(run.RequestId)
+		running[addr] = true
+		d.started[run.RequestId] = addr
+		go d.startRun(dg, run)
+	}
+	return nil
+}
+
+// startRun runs the queued run. The run is removed from the queue once the
+// agent listens on the socket so that the run is not lost if the agent
+// refuses to start it.
+func (d *Dispatcher) startRun(dg *dag.DAG, run *model.QueuedRun) {
+	defer func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.started, run.RequestId)
+	}()
+
+	cmd := d.engine.startCommand(dg, run.Params, run.RequestId, run.ScheduledAt)
+	if err := cmd.Start(); err != nil {
+		util.LogErr("starting a DAG", err)
+		util.LogErr("removing a queued run", d.queueStore.Remove(run.RequestId))
+		return
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	addr := dg.SockAddr(run.RequestId)
+	ticker := time.NewTicker(startCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-exited:
+			util.LogErr("starting a DAG", err)
+			d.finishStart(dg, run)
+			return
+		case <-ticker.C:
+			if !sock.IsListening(addr) {
+				continue
+			}
+			if err := d.queueStore.Remove(run.RequestId); err != nil {
+				// The run was stopped while it was starting.
+				util.LogErr("stopping a DAG", stopRun(dg, run.RequestId))
+			}
+			util.LogErr("running a DAG", <-exited)
+			return
+		}
+	}
+}
+
+// finishStart removes the run whose agent exited before it was seen
+// listening from the queue. The run stays in the queue if it was not
+// recorded in the history and its DAG is running as many runs as it
+// allows, because the agent refused to start it then. Otherwise it is
+// removed so that a run that can not start is not retried forever.
+func (d *Dispatcher) finishStart(dg *dag.DAG, run *model.QueuedRun) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.started, run.RequestId)
+
+	hs := d.engine.dataStoreFactory.NewHistoryStore()
+	if _, err := hs.FindByRequestId(dg.Location, run.RequestId); err != nil {
+		running, err := d.runningAddrs()
+		if err == nil {
+			if countMatches(running, dg.SockAddrPattern()) >= dg.RunLimit() {
+				log.Printf("the queued run %s of %s was refused to start and stays in the queue", run.RequestId, dg.Name)
+				return
+			}
+		}
+	}
+	util.LogErr("removing a queued run", d.queueStore.Remove(run.RequestId))
+}
+
+// acquireLock returns true if the dispatcher holds the lock file.
+func (d *Dispatcher) acquireLock() bool {
+	if d.lock != nil {
+		return true
+	}
+	if err := os.MkdirAll(filepath.Dir(d.lockFile), 0755); err != nil {
+		d.lockFailure("failed to create the directory of the dispatcher lock: %v", err)
+		return false
+	}
+	f, err := os.OpenFile(d.lockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		d.lockFailure("failed to open the dispatcher lock: %v", err)
+		return false
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		// the process holding the lock writes its PID to the file
+		pid, _ := os.ReadFile(d.lockFile)
+		d.lockFailure("the queued runs are dispatched by the process %s holding %s", strings.TrimSpace(string(pid)), d.lockFile)
+		return false
+	}
+	_ = f.Truncate(0)
+	_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	if d.lockFailed {
+		log.Printf("acquired the dispatcher lock %s", d.lockFile)
+	}
+	d.lockFailed = false
+	d.lock = f
+	return true
+}
+
+// lockFailure logs the failure to acquire the lock if it is the first one
+// since the lock was last acquired.
+func (d *Dispatcher) lockFailure(format string, v ...any) {
+	if !d.lockFailed {
+		log.Printf(format, v...)
+	}
+	d.lockFailed = true
+}
+
+// runningAddrs returns the socket addresses of the running runs of all
+// the DAGs including the runs started by the dispatcher.
+func (d *Dispatcher) runningAddrs() (map[string]bool, error) {
+	addrs, err := filepath.Glob(dag.SockAddrPatternAll())
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]bool{}
+	for _, addr := range addrs {
+		if sock.IsListening(addr) {
+			ret[addr] = true
+		}
+	}
+	for _, addr := range d.started {
+		ret[addr] = true
+	}
+	return ret, nil
+}
+
+// countMatches returns the number of the addresses that match the pattern.
+func countMatches(addrs map[string]bool, pattern string) int {
+	n := 0
+	for addr := range addrs {
+		if ok, _ := filepath.Match(pattern, addr); ok {
+			n++
+		}
+	}
+	return n
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/internal/persistence"
@@ -14,6 +17,7 @@ import (
 	"github.com/dagu-dev/dagu/internal/scheduler"
 	"github.com/dagu-dev/dagu/internal/sock"
 	"github.com/dagu-dev/dagu/internal/util"
+	"github.com/google/uuid"
 )
 
 type Engine interface {
@@ -21,22 +25,46 @@ type Engine interface {
 	GetDAGSpec(id string) (string, error)
 	Grep(pattern string) ([]*persistence.GrepResult, []string, error)
 	Rename(oldDAGPath, newDAGPath string) error
-	Stop(d *dag.DAG) error
-	StartAsync(d *dag.DAG, params string)
+	// Stop stops the run of the DAG with the request ID. All the runs of
+	// the DAG are stopped if the request ID is empty. The queued runs are
+	// removed from the run queue.
+	Stop(d *dag.DAG, requestId string) error
+	// StartAsync adds a run of the DAG to the run queue. The run is started
+	// by the dispatcher when the number of running DAGs is below the limit.
+	StartAsync(d *dag.DAG, params string) error
+	// StartScheduled adds a scheduled run of the DAG to the run queue. The
+	// run gets the schedule time in the DAG_SCHEDULED_TIME environment variable.
+	StartScheduled(d *dag.DAG, scheduledAt time.Time) error
 	Start(d *dag.DAG, params string) error
+	// RecordSkipped records a scheduled run of the DAG that was skipped
+	// because the DAG was running.
+	RecordSkipped(d *dag.DAG, scheduledAt time.Time) error
 	Restart(d *dag.DAG) error
 	Retry(d *dag.DAG, reqId string) error
+	// GetCurrentStatus returns the status of the latest running run of the DAG.
 	GetCurrentStatus(d *dag.DAG) (*model.Status, error)
+	// GetRunningStatuses returns the statuses of the running runs of the DAG
+	// in descending order of the start time.
+	GetRunningStatuses(d *dag.DAG) ([]*model.Status, error)
+	// GetQueuedStatuses returns the statuses of the runs of the DAG in the
+	// run queue in the order they are started.
+	GetQueuedStatuses(d *dag.DAG) ([]*model.Status, error)
 	GetStatusByRequestId(d *dag.DAG, requestId string) (*model.Status, error)
 	GetLatestStatus(d *dag.DAG) (*model.Status, error)
 	GetRecentHistory(d *dag.DAG, n int) []*model.StatusFile
 	UpdateStatus(d *dag.DAG, status *model.Status) error
-	UpdateDAG(id string, spec string) error
+	UpdateDAG(id string, spec string, revision string) error
 	DeleteDAG(name, loc string) error
 	GetAllStatus() (statuses []*persistence.DAGStatus, errs []string, err error)
 	GetStatus(dagLocation string) (*persistence.DAGStatus, error)
 	IsSuspended(id string) bool
+	// ToggleSuspend suspends or resumes the schedule of the DAG. The runs
+	// missed while the DAG was suspended are not caught up.
 	ToggleSuspend(id string, suspend bool) error
+	// GetLastTick returns the last schedule time that the scheduler handled
+	// for the DAG, or the zero time if there is none.
+	GetLastTick(d *dag.DAG) (time.Time, error)
+	SetLastTick(d *dag.DAG, t time.Time) error
 }
 
 type engineImpl struct {
@@ -57,6 +85,7 @@ var (
 	errRenameDAG     = errors.New("failed to rename DAG")
 	errGetStatus     = errors.New("failed to get status")
 	errDAGIsRunning  = errors.New("the DAG is running")
+	errRunNotFound   = errors.New("the run is not running")
 )
 
 func (e *engineImpl) GetDAGSpec(id string) (string, error) {
@@ -90,39 +119,113 @@ func (e *engineImpl) Rename(oldName, newName string) error {
 	return nil
 }
 
-func (e *engineImpl) Stop(d *dag.DAG) error {
+func (e *engineImpl) Stop(d *dag.DAG, requestId string) error {
+	queued, err := e.GetQueuedStatuses(d)
+	if err != nil {
+		return err
+	}
+	qs := e.dataStoreFactory.NewQueueStore()
+	if requestId != "" {
+		for _, st := range queued {
+			if st.RequestId == requestId {
+				return qs.Remove(requestId)
+			}
+		}
+		if _, err := e.getRunningStatus(d, requestId); err != nil {
+			return err
+		}
+		return stopRun(d, requestId)
+	}
+	var errs []error
+	for _, st := range queued {
+		errs = append(errs, qs.Remove(st.RequestId))
+	}
+	statuses, err := e.GetRunningStatuses(d)
+	if err != nil {
+		return err
+	}
+	for _, st := range statuses {
+		errs = append(errs, stopRun(d, st.RequestId))
+	}
+	return errors.Join(errs...)
+}
+
+func stopRun(d *dag.DAG, requestId string) error {
 	// TODO: fix this not to connect to the DAG directly
-	client := sock.Client{Addr: d.SockAddr()}
+	client := sock.Client{Addr: d.SockAddr(requestId)}
 	_, err := client.Request("POST", "/stop")
 	return err
 }
 
-func (e *engineImpl) StartAsync(d *dag.DAG, params string) {
-	go func() {
-		err := e.Start(d, params)
-		util.LogErr("starting a DAG", err)
-	}()
+func (e *engineImpl) StartAsync(d *dag.DAG, params string) error {
+	return e.enqueue(d, params, "")
+}
+
+func (e *engineImpl) StartScheduled(d *dag.DAG, scheduledAt time.Time) error {
+	return e.enqueue(d, "", util.FormatTime(scheduledAt))
+}
+
+func (e *engineImpl) enqueue(d *dag.DAG, params, scheduledAt string) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	return e.dataStoreFactory.NewQueueStore().Enqueue(&model.QueuedRun{
+		RequestId:   id.String(),
+		DAGFile:     d.Location,
+		Name:        d.Name,
+		Params:      params,
+		Priority:    d.Priority,
+		QueuedAt:    util.FormatTime(time.Now()),
+		ScheduledAt: scheduledAt,
+	})
+}
+
+func (e *engineImpl) RecordSkipped(d *dag.DAG, scheduledAt time.Time) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	status := model.NewStatusSkipped(d, id.String(), scheduledAt)
+	return e.dataStoreFactory.NewHistoryStore().Record(d.Location, time.Now(), status)
 }
 
 func (e *engineImpl) Start(d *dag.DAG, params string) error {
+	return e.start(d, params, "", "")
+}
+
+// start runs the DAG with the request ID. A new request ID is generated
+// if it is empty. The schedule time is set to the environment variable
+// of the run if it is not empty.
+func (e *engineImpl) start(d *dag.DAG, params, requestId, scheduledAt string) error {
+	cmd := e.startCommand(d, params, requestId, scheduledAt)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Wait()
+}
+
+// startCommand returns the command that runs the DAG.
+func (e *engineImpl) startCommand(d *dag.DAG, params, requestId, scheduledAt string) *exec.Cmd {
 	args := []string{"start"}
 	if params != "" {
 		args = append(args, "-p")
 		args = append(args, fmt.Sprintf(`"%s"`, escapeArg(params, false)))
 	}
+	if requestId != "" {
+		args = append(args, fmt.Sprintf("--req=%s", requestId))
+	}
 	args = append(args, d.Location)
 	cmd := exec.Command(e.executable, args...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pgid: 0}
 	cmd.Dir = e.workDir
 	cmd.Env = os.Environ()
+	if scheduledAt != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", dag.EnvScheduledTime, scheduledAt))
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-
-	err := cmd.Start()
-	if err != nil {
-		return err
-	}
-	return cmd.Wait()
+	return cmd
 }
 
 func (e *engineImpl) Restart(d *dag.DAG) error {
@@ -154,43 +257,98 @@ func (e *engineImpl) Retry(d *dag.DAG, reqId string) error {
 }
 
 func (e *engineImpl) GetCurrentStatus(d *dag.DAG) (*model.Status, error) {
-	client := sock.Client{Addr: d.SockAddr()}
-	ret, err := client.Request("GET", "/status")
+	statuses, err := e.GetRunningStatuses(d)
 	if err != nil {
-		if errors.Is(err, sock.ErrTimeout) {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return model.NewStatusDefault(d), nil
+	}
+	return statuses[0], nil
+}
+
+func (e *engineImpl) GetRunningStatuses(d *dag.DAG) ([]*model.Status, error) {
+	addrs, err := filepath.Glob(d.SockAddrPattern())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errGetStatus, err)
+	}
+	ret := []*model.Status{}
+	for _, addr := range addrs {
+		client := sock.Client{Addr: addr}
+		res, err := client.Request("GET", "/status")
+		if err != nil {
+			if errors.Is(err, sock.ErrTimeout) {
+				return nil, err
+			}
+			// The socket is left by a run that did not exit normally.
+			continue
+		}
+		status, err := model.StatusFromJson(res)
+		if err != nil {
 			return nil, err
 		}
-		return model.NewStatusDefault(d), nil
+		ret = append(ret, status)
 	}
-	return model.StatusFromJson(ret)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].StartedAt > ret[j].StartedAt
+	})
+	return ret, nil
 }
 
-func (e *engineImpl) GetStatusByRequestId(d *dag.DAG, requestId string) (*model.Status, error) {
-	ret, err := e.dataStoreFactory.NewHistoryStore().FindByRequestId(d.Location, requestId)
+func (e *engineImpl) GetQueuedStatuses(d *dag.DAG) ([]*model.Status, error) {
+	runs, err := e.dataStoreFactory.NewQueueStore().List()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errGetStatus, err)
+	}
+	ret := []*model.Status{}
+	for _, run := range runs {
+		if run.DAGFile == d.Location {
+			ret = append(ret, model.NewStatusQueued(d, run))
+		}
+	}
+	return ret, nil
+}
+
+// getRunningStatus returns the status of the running run of the DAG with the request ID.
+func (e *engineImpl) getRunningStatus(d *dag.DAG, requestId string) (*model.Status, error) {
+	client := sock.Client{Addr: d.SockAddr(requestId)}
+	res, err := client.Request("GET", "/status")
+	if err != nil {
+		if errors.Is(err, sock.ErrTimeout) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", errRunNotFound, requestId)
+	}
+	status, err := model.StatusFromJson(res)
 	if err != nil {
 		return nil, err
 	}
-	status, _ := e.GetCurrentStatus(d)
-	if status != nil && status.RequestId != requestId {
-		// if the request id is not matched then correct the status
-		ret.Status.CorrectRunningStatus()
+	// The address has only a prefix of the request ID.
+	if status.RequestId != requestId {
+		return nil, fmt.Errorf("%w: %s", errRunNotFound, requestId)
 	}
-	return ret.Status, err
+	return status, nil
 }
 
-func (e *engineImpl) getCurrentStatus(d *dag.DAG) (*model.Status, error) {
-	client := sock.Client{Addr: d.SockAddr()}
-	ret, err := client.Request("GET", "/status")
+func (e *engineImpl) GetStatusByRequestId(d *dag.DAG, requestId string) (*model.Status, error) {
+	ret, err := e.dataStoreFactory.NewHistoryStore().FindByRequestId(d.Location, requestId)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", errGetStatus, err)
+		return nil, err
+	}
+	if _, err := e.getRunningStatus(d, requestId); errors.Is(err, errRunNotFound) {
+		// the run is not running so correct the status
+		ret.Status.CorrectRunningStatus()
 	}
-	return model.StatusFromJson(ret)
+	return ret.Status, nil
 }
 
 func (e *engineImpl) GetLatestStatus(d *dag.DAG) (*model.Status, error) {
-	currStatus, _ := e.getCurrentStatus(d)
-	if currStatus != nil {
-		return currStatus, nil
+	statuses, _ := e.GetRunningStatuses(d)
+	if len(statuses) > 0 {
+		return statuses[0], nil
+	}
+	if queued, _ := e.GetQueuedStatuses(d); len(queued) > 0 {
+		return queued[0], nil
 	}
 	status, err := e.dataStoreFactory.NewHistoryStore().ReadStatusToday(d.Location)
 	if errors.Is(err, persistence.ErrNoStatusDataToday) || errors.Is(err, persistence.ErrNoStatusData) {
@@ -199,34 +357,64 @@ func (e *engineImpl) GetLatestStatus(d *dag.DAG) (*model.Status, error) {
 	if err != nil {
 		return model.NewStatusDefault(d), err
 	}
+	if status.Status == scheduler.StatusSkipped {
+		// A skipped run does not hide the status of the run before it.
+		status = e.latestNotSkipped(d, status)
+	}
 	status.CorrectRunningStatus()
 	return status, nil
 }
 
+// latestSkippedLookback is the number of the recent runs to look back
+// for a run that was not skipped.
+const latestSkippedLookback = 10
+
+// latestNotSkipped returns the status of the latest run of the DAG that
+// was not skipped, or the skipped status if there is no such run recently.
+func (e *engineImpl) latestNotSkipped(d *dag.DAG, skipped *model.Status) *model.Status {
+	for _, f := range e.GetRecentHistory(d, latestSkippedLookback) {
+		if f.Status.Status != scheduler.StatusSkipped {
+			return f.Status
+		}
+	}
+	return skipped
+}
+
 func (e *engineImpl) GetRecentHistory(d *dag.DAG, n int) []*model.StatusFile {
 	return e.dataStoreFactory.NewHistoryStore().ReadStatusRecent(d.Location, n)
 }
 
 func (e *engineImpl) UpdateStatus(d *dag.DAG, status *model.Status) error {
-	client := sock.Client{Addr: d.SockAddr()}
-	res, err := client.Request("GET", "/status")
-	if err != nil {
-		if errors.Is(err, sock.ErrTimeout) {
-			return err
-		}
-	} else {
-		ss, _ := model.StatusFromJson(res)
-		if ss != nil && ss.RequestId == status.RequestId &&
-			ss.Status == scheduler.StatusRunning {
-			return errDAGIsRunning
-		}
+	ss, err := e.getRunningStatus(d, status.RequestId)
+	if err != nil && !errors.Is(err, errRunNotFound) {
+		return err
+	}
+	if ss != nil && ss.Status == scheduler.StatusRunning {
+		return errDAGIsRunning
 	}
 	return e.dataStoreFactory.NewHistoryStore().Update(d.Location, status.RequestId, status)
 }
 
-func (e *engineImpl) UpdateDAG(id string, spec string) error {
+func (e *engineImpl) UpdateDAG(id string, spec string, revision string) error {
+	// The spec is validated before the file is overwritten because a broken
+	// file stops the DAG from being scheduled. It is validated at the location
+	// of the file so that the includes are resolved in the same way.
 	ds := e.dataStoreFactory.NewDAGStore()
-	return ds.UpdateSpec(id, []byte(spec))
+	loc, err := ds.Location(id)
+	if err != nil {
+		return err
+	}
+	if err := dag.ValidateYAMLAt(loc, []byte(spec), validateGraph); err != nil {
+		return err
+	}
+	return ds.UpdateSpec(id, []byte(spec), revision)
+}
+
+// validateGraph returns an error if the steps of the DAG can not be run
+// because of a dependency cycle or a dependency on a missing step.
+func validateGraph(d *dag.DAG) error {
+	_, err := scheduler.NewExecutionGraph(d.Steps...)
+	return err
 }
 
 func (e *engineImpl) DeleteDAG(name, loc string) error {
@@ -280,7 +468,30 @@ func (e *engineImpl) GetStatus(id string) (*persistence.DAGStatus, error) {
 
 func (e *engineImpl) ToggleSuspend(id string, suspend bool) error {
 	fs := e.dataStoreFactory.NewFlagStore()
-	return fs.ToggleSuspend(id, suspend)
+	if err := fs.ToggleSuspend(id, suspend); err != nil {
+		return err
+	}
+	if suspend {
+		return nil
+	}
+	// The DAG is caught up from now on when it is resumed.
+	loc, err := e.dataStoreFactory.NewDAGStore().Location(id)
+	if err != nil {
+		return err
+	}
+	ts := e.dataStoreFactory.NewTickStore()
+	if last, err := ts.GetLastTick(loc); err != nil || last.IsZero() {
+		return err
+	}
+	return ts.SetLastTick(loc, time.Now())
+}
+
+func (e *engineImpl) GetLastTick(d *dag.DAG) (time.Time, error) {
+	return e.dataStoreFactory.NewTickStore().GetLastTick(d.Location)
+}
+
+func (e *engineImpl) SetLastTick(d *dag.DAG, t time.Time) error {
+	return e.dataStoreFactory.NewTickStore().SetLastTick(d.Location, t)
 }
 
 func (e *engineImpl) readStatus(d *dag.DAG) (*persistence.DAGStatus, error) {
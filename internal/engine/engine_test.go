@@ -1,10 +1,16 @@
 package engine_test
 
 import (
+	"bytes"
+	"log"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -76,7 +82,7 @@ func TestGetStatusRunningAndDone(t *testing.T) {
 
 	socketServer, _ := sock.NewServer(
 		&sock.Config{
-			Addr: ds.DAG.SockAddr(),
+			Addr: ds.DAG.SockAddr("test-running"),
 			HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
 				status := model.NewStatus(ds.DAG, nil, scheduler.StatusRunning, 0, nil, nil)
 				w.WriteHeader(http.StatusOK)
@@ -102,6 +108,66 @@ func TestGetStatusRunningAndDone(t *testing.T) {
 	require.Equal(t, scheduler.StatusNone, st.Status)
 }
 
+func TestGetRunningStatuses(t *testing.T) {
+	tmpDir, e, _ := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+	file := testDAG("get_status.yaml")
+
+	ds, err := e.GetStatus(file)
+	require.NoError(t, err)
+
+	// Serve the status of two runs of the DAG.
+	stopped := make(chan string, 2)
+	now := time.Now()
+	for i, reqId := range []string{"run-1", "run-2"} {
+		startedAt := now.Add(time.Duration(i) * time.Second)
+		socketServer, _ := sock.NewServer(
+			&sock.Config{
+				Addr: ds.DAG.SockAddr(reqId),
+				HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodPost {
+						stopped <- reqId
+					}
+					status := model.NewStatus(ds.DAG, nil, scheduler.StatusRunning, 0, &startedAt, nil)
+					status.RequestId = reqId
+					w.WriteHeader(http.StatusOK)
+					b, _ := status.ToJson()
+					_, _ = w.Write(b)
+				},
+			})
+		go func() {
+			_ = socketServer.Serve(nil)
+		}()
+		defer func() {
+			_ = socketServer.Shutdown()
+		}()
+	}
+	time.Sleep(time.Millisecond * 100)
+
+	statuses, err := e.GetRunningStatuses(ds.DAG)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	// The latest run comes first.
+	require.Equal(t, "run-2", statuses[0].RequestId)
+	require.Equal(t, "run-1", statuses[1].RequestId)
+
+	st, err := e.GetCurrentStatus(ds.DAG)
+	require.NoError(t, err)
+	require.Equal(t, "run-2", st.RequestId)
+
+	// Stop only the run with the request ID.
+	err = e.Stop(ds.DAG, "run-1")
+	require.NoError(t, err)
+	require.Equal(t, "run-1", <-stopped)
+
+	err = e.Stop(ds.DAG, "run-3")
+	require.Error(t, err)
+	require.Empty(t, stopped)
+}
+
 func TestUpdateStatus(t *testing.T) {
 	tmpDir, e, hf := setupTest(t)
 	defer func() {
@@ -192,7 +258,7 @@ func TestStart(t *testing.T) {
 }
 
 func TestStop(t *testing.T) {
-	tmpDir, e, _ := setupTest(t)
+	tmpDir, e, ds := setupTest(t)
 	defer func() {
 		_ = os.RemoveAll(tmpDir)
 	}()
@@ -202,14 +268,19 @@ func TestStop(t *testing.T) {
 	d, err := e.GetStatus(file)
 	require.NoError(t, err)
 
-	e.StartAsync(d.DAG, "")
+	dp := newDispatcher(tmpDir, ds, 0)
+	dp.Start()
+	defer dp.Stop()
+
+	err = e.StartAsync(d.DAG, "")
+	require.NoError(t, err)
 
 	require.Eventually(t, func() bool {
 		st, _ := e.GetCurrentStatus(d.DAG)
 		return st.Status == scheduler.StatusRunning
 	}, time.Millisecond*1500, time.Millisecond*100)
 
-	_ = e.Stop(d.DAG)
+	_ = e.Stop(d.DAG, "")
 
 	require.Eventually(t, func() bool {
 		st, _ := e.GetLatestStatus(d.DAG)
@@ -217,6 +288,244 @@ func TestStop(t *testing.T) {
 	}, time.Millisecond*1500, time.Millisecond*100)
 }
 
+func TestStopQueued(t *testing.T) {
+	tmpDir, e, _ := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d, err := e.GetStatus(testDAG("stop.yaml"))
+	require.NoError(t, err)
+
+	// no dispatcher is running, so the run stays in the queue
+	err = e.StartAsync(d.DAG, "")
+	require.NoError(t, err)
+
+	st, err := e.GetLatestStatus(d.DAG)
+	require.NoError(t, err)
+	require.Equal(t, scheduler.StatusQueued, st.Status)
+	require.Equal(t, "queued", st.StatusText)
+
+	err = e.Stop(d.DAG, st.RequestId)
+	require.NoError(t, err)
+
+	queued, err := e.GetQueuedStatuses(d.DAG)
+	require.NoError(t, err)
+	require.Empty(t, queued)
+}
+
+func TestRecordSkipped(t *testing.T) {
+	tmpDir, e, hf := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d, err := e.GetStatus(testDAG("get_status.yaml"))
+	require.NoError(t, err)
+
+	hs := hf.NewHistoryStore()
+	require.NoError(t, hs.Open(d.DAG.Location, time.Now().Add(-time.Minute), "test-finished"))
+	require.NoError(t, hs.Write(testNewStatus(d.DAG, "test-finished",
+		scheduler.StatusSuccess, scheduler.NodeStatusSuccess)))
+	_ = hs.Close()
+
+	err = e.RecordSkipped(d.DAG, time.Now())
+	require.NoError(t, err)
+
+	history := e.GetRecentHistory(d.DAG, 2)
+	require.Len(t, history, 2)
+	require.Equal(t, scheduler.StatusSkipped, history[0].Status.Status)
+
+	// the skipped run does not hide the status of the finished run
+	st, err := e.GetLatestStatus(d.DAG)
+	require.NoError(t, err)
+	require.Equal(t, "test-finished", st.RequestId)
+}
+
+func TestDispatcher(t *testing.T) {
+	tmpDir, e, ds := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	low, err := e.GetStatus(testDAG("queue_low.yaml"))
+	require.NoError(t, err)
+	high, err := e.GetStatus(testDAG("queue_high.yaml"))
+	require.NoError(t, err)
+
+	require.NoError(t, e.StartAsync(low.DAG, ""))
+	require.NoError(t, e.StartAsync(high.DAG, ""))
+
+	dp := newDispatcher(tmpDir, ds, 1)
+	dp.Start()
+	defer dp.Stop()
+
+	// the run with the higher priority is started first
+	require.Eventually(t, func() bool {
+		st, _ := e.GetCurrentStatus(high.DAG)
+		return st.Status == scheduler.StatusRunning
+	}, time.Second*10, time.Millisecond*100)
+
+	st, err := e.GetLatestStatus(low.DAG)
+	require.NoError(t, err)
+	require.Equal(t, scheduler.StatusQueued, st.Status)
+
+	require.Eventually(t, func() bool {
+		st, _ := e.GetLatestStatus(low.DAG)
+		return st.Status == scheduler.StatusSuccess
+	}, time.Second*15, time.Millisecond*100)
+}
+
+func TestDispatcherLock(t *testing.T) {
+	tmpDir, _, ds := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	var buf syncBuffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	dp1 := newDispatcher(tmpDir, ds, 1)
+	dp1.Start()
+	lockFile := path.Join(tmpDir, ".dagu", "data", "dispatcher.lock")
+	require.Eventually(t, func() bool {
+		pid, _ := os.ReadFile(lockFile)
+		return string(pid) == strconv.Itoa(os.Getpid())
+	}, time.Second*5, time.Millisecond*100)
+
+	// the dispatcher that fails to acquire the lock logs it only once
+	dp2 := newDispatcher(tmpDir, ds, 1)
+	dp2.Start()
+	time.Sleep(time.Millisecond * 2500)
+	require.Equal(t, 1, strings.Count(buf.String(), "the queued runs are dispatched by the process"), buf.String())
+
+	// stopping twice does not panic
+	dp1.Stop()
+	dp1.Stop()
+	dp2.Stop()
+	dp2.Stop()
+}
+
+// syncBuffer is a buffer that is safe for concurrent writes by the logger.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDispatcherRefusedStart(t *testing.T) {
+	tmpDir, e, ds := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	st, err := e.GetStatus(testDAG("queue_low.yaml"))
+	require.NoError(t, err)
+	d := st.DAG
+	require.NoError(t, e.StartAsync(d, ""))
+
+	// The first start exits without listening like an agent that refuses to
+	// start because another run of the DAG was started at the same time.
+	started := path.Join(tmpDir, "started")
+	refused := path.Join(tmpDir, "refused")
+	executable := path.Join(tmpDir, "dagu.sh")
+	require.NoError(t, os.WriteFile(executable, []byte(`#!/bin/sh
+if [ ! -f `+started+` ]; then
+  touch `+started+`
+  sleep 1
+  touch `+refused+`
+  exit 1
+fi
+exec `+path.Join(util.MustGetwd(), "../../bin/dagu")+` "$@"
+`), 0755))
+
+	dp := engine.NewDispatcher(ds, &config.Config{
+		DataDir:    path.Join(tmpDir, ".dagu", "data"),
+		Executable: executable,
+	})
+	dp.Start()
+	defer dp.Stop()
+
+	require.Eventually(t, func() bool {
+		return util.FileExists(started)
+	}, time.Second*5, time.Millisecond*100)
+
+	// Another run of the DAG is running when the agent exits.
+	server, err := sock.NewServer(&sock.Config{
+		Addr: d.SockAddr("other"),
+		HandlerFunc: func(w http.ResponseWriter, _ *http.Request) {
+			status := model.NewStatus(d, nil, scheduler.StatusRunning, 0, nil, nil)
+			status.RequestId = "other"
+			w.WriteHeader(http.StatusOK)
+			b, _ := status.ToJson()
+			_, _ = w.Write(b)
+		},
+	})
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(nil)
+	}()
+
+	require.Eventually(t, func() bool {
+		return util.FileExists(refused)
+	}, time.Second*5, time.Millisecond*100)
+	time.Sleep(time.Millisecond * 500)
+
+	// The refused run stays in the queue.
+	queued, err := e.GetQueuedStatuses(d)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+
+	// The run is started again after the other run finishes.
+	require.NoError(t, server.Shutdown())
+	require.Eventually(t, func() bool {
+		st, _ := e.GetLatestStatus(d)
+		return st.Status == scheduler.StatusSuccess
+	}, time.Second*15, time.Millisecond*100)
+	queued, err = e.GetQueuedStatuses(d)
+	require.NoError(t, err)
+	require.Empty(t, queued)
+}
+
+func TestStartScheduled(t *testing.T) {
+	tmpDir, e, ds := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d, err := e.GetStatus(testDAG("scheduled_time.yaml"))
+	require.NoError(t, err)
+
+	scheduledAt := time.Date(2020, 1, 1, 1, 0, 0, 0, time.Local)
+	require.NoError(t, e.StartScheduled(d.DAG, scheduledAt))
+
+	dp := newDispatcher(tmpDir, ds, 0)
+	dp.Start()
+	defer dp.Stop()
+
+	// the run gets the schedule time in the environment variable
+	require.Eventually(t, func() bool {
+		st, _ := e.GetLatestStatus(d.DAG)
+		return st.Status == scheduler.StatusSuccess
+	}, time.Second*10, time.Millisecond*100)
+
+	st, err := e.GetLatestStatus(d.DAG)
+	require.NoError(t, err)
+	require.Equal(t, util.FormatTime(scheduledAt), st.OutputVariables["SCHEDULED_TIME"])
+}
+
 func TestRestart(t *testing.T) {
 	tmpDir, e, _ := setupTest(t)
 	defer func() {
@@ -286,7 +595,7 @@ steps:
     command: "true"
 `
 	// Update Error: the DAG does not exist
-	err := e.UpdateDAG("non-existing-dag", validDAG)
+	err := e.UpdateDAG("non-existing-dag", validDAG, "")
 	require.Error(t, err)
 
 	// create a new DAG file
@@ -294,7 +603,7 @@ steps:
 	require.NoError(t, err)
 
 	// Update the DAG
-	err = e.UpdateDAG(id, validDAG)
+	err = e.UpdateDAG(id, validDAG, "")
 	require.NoError(t, err)
 
 	// Check the content of the DAG file
@@ -303,6 +612,171 @@ steps:
 	require.Equal(t, validDAG, spec)
 }
 
+func TestUpdateConflict(t *testing.T) {
+	tmpDir, e, _ := setupTestTmpDir(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	id, err := e.CreateDAG("conflict")
+	require.NoError(t, err)
+	orig, err := e.GetDAGSpec(id)
+	require.NoError(t, err)
+	revision := persistence.SpecRevision([]byte(orig))
+
+	first := `steps:
+  - name: "1"
+    command: "true"
+`
+	second := `steps:
+  - name: "2"
+    command: "true"
+`
+	// The first save succeeds with the revision of the original spec.
+	err = e.UpdateDAG(id, first, revision)
+	require.NoError(t, err)
+
+	// The second save with the same revision is stale.
+	err = e.UpdateDAG(id, second, revision)
+	require.ErrorIs(t, err, persistence.ErrSpecConflict)
+
+	spec, err := e.GetDAGSpec(id)
+	require.NoError(t, err)
+	require.Equal(t, first, spec)
+
+	// The save succeeds with the revision of the current spec.
+	err = e.UpdateDAG(id, second, persistence.SpecRevision([]byte(spec)))
+	require.NoError(t, err)
+}
+
+func TestUpdateInvalidSpec(t *testing.T) {
+	tmpDir, e, _ := setupTestTmpDir(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	id, err := e.CreateDAG("invalid-spec")
+	require.NoError(t, err)
+	orig, err := e.GetDAGSpec(id)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		spec     string
+		line     int
+		expected string
+	}{
+		{
+			name: "InvalidSchedule",
+			spec: `schedule: "invalid"
+steps:
+  - name: "1"
+    command: "true"
+`,
+			line:     1,
+			expected: "invalid schedule",
+		},
+		{
+			name: "InvalidStep",
+			spec: `steps:
+  - name: "1"
+    command: "true"
+  - name: "2"
+    depends: ["1"]
+`,
+			line:     4,
+			expected: "either step command or step call must be specified",
+		},
+		{
+			name: "Cycle",
+			spec: `steps:
+  - name: "1"
+    command: "true"
+    depends: ["2"]
+  - name: "2"
+    command: "true"
+    depends: ["1"]
+`,
+			line:     1,
+			expected: "cycle detected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.UpdateDAG(id, tt.spec, "")
+			var verr *dag.ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.Contains(t, err.Error(), tt.expected)
+			require.True(t, slices.ContainsFunc(verr.Diagnostics, func(d dag.Diagnostic) bool {
+				return d.Severity == dag.SeverityError && d.Line == tt.line
+			}), "%v", verr.Diagnostics)
+
+			// The file is not overwritten.
+			spec, err := e.GetDAGSpec(id)
+			require.NoError(t, err)
+			require.Equal(t, orig, spec)
+		})
+	}
+}
+
+func TestUpdateNestedDAG(t *testing.T) {
+	tmpDir, e, _ := setupTestTmpDir(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	id, err := e.CreateDAG("team/etl")
+	require.NoError(t, err)
+
+	// The fragment is next to the DAG file in the folder.
+	fragment := path.Join(tmpDir, ".dagu", "dags", "team", "common.yaml")
+	require.NoError(t, os.WriteFile(fragment, []byte("env:\n  - TEAM: team\n"), 0600))
+
+	spec := `include:
+  - common.yaml
+steps:
+  - name: "1"
+    command: echo $TEAM
+`
+	require.NoError(t, e.UpdateDAG(id, spec, ""))
+
+	saved, err := e.GetDAGSpec(id)
+	require.NoError(t, err)
+	require.Equal(t, spec, saved)
+}
+
+func TestLastTickNestedDAG(t *testing.T) {
+	tmpDir, e, _ := setupTestTmpDir(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	spec := "steps:\n  - name: \"1\"\n    command: \"true\"\n"
+	var dags []*dag.DAG
+	for _, name := range []string{"etl", "team/etl"} {
+		id, err := e.CreateDAG(name)
+		require.NoError(t, err)
+		require.NoError(t, e.UpdateDAG(id, spec, ""))
+		status, err := e.GetStatus(id)
+		require.NoError(t, err)
+		dags = append(dags, status.DAG)
+	}
+
+	// The DAGs with the same name in different folders have their own ticks.
+	last := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, e.SetLastTick(dags[1], last))
+	tick, err := e.GetLastTick(dags[0])
+	require.NoError(t, err)
+	require.True(t, tick.IsZero())
+
+	// The tick is reset when the DAG is resumed.
+	require.NoError(t, e.ToggleSuspend("team/etl", true))
+	require.NoError(t, e.ToggleSuspend("team/etl", false))
+	tick, err = e.GetLastTick(dags[1])
+	require.NoError(t, err)
+	require.True(t, tick.After(last))
+}
+
 func TestRemove(t *testing.T) {
 	tmpDir, e, _ := setupTestTmpDir(t)
 	defer func() {
@@ -316,7 +790,7 @@ steps:
 `
 	id, err := e.CreateDAG("test")
 	require.NoError(t, err)
-	err = e.UpdateDAG(id, spec)
+	err = e.UpdateDAG(id, spec, "")
 	require.NoError(t, err)
 
 	// check file
@@ -414,6 +888,14 @@ func TestReadDAGStatus(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func newDispatcher(tmpDir string, ds persistence.DataStoreFactory, maxRunningDAGs int) *engine.Dispatcher {
+	return engine.NewDispatcher(ds, &config.Config{
+		DataDir:        path.Join(tmpDir, ".dagu", "data"),
+		Executable:     path.Join(util.MustGetwd(), "../../bin/dagu"),
+		MaxRunningDAGs: maxRunningDAGs,
+	})
+}
+
 func testDAG(name string) string {
 	return path.Join(testdataDir, name)
 }
@@ -76,16 +76,16 @@ func (e *HTTPExecutor) Run() error {
 func CreateHTTPExecutor(ctx context.Context, step dag.Step) (Executor, error) {
 	var reqCfg HTTPConfig
 	if len(step.Script) > 0 {
-		if err := decodeHTTPConfigFromString(step.Script, &reqCfg); err != nil {
+		if err := decodeHTTPConfigFromString(step.ExpandEnv(step.Script), &reqCfg); err != nil {
 			return nil, err
 		}
 	} else if step.ExecutorConfig.Config != nil {
 		if err := decodeHTTPConfig(step.ExecutorConfig.Config, &reqCfg); err != nil {
 			return nil, err
 		}
-		reqCfg.Body = os.ExpandEnv(reqCfg.Body)
+		reqCfg.Body = step.ExpandEnv(reqCfg.Body)
 		for k, v := range reqCfg.Headers {
-			reqCfg.Headers[k] = os.ExpandEnv(v)
+			reqCfg.Headers[k] = step.ExpandEnv(v)
 		}
 	}
 
@@ -126,8 +126,7 @@ func decodeHTTPConfig(dat map[string]interface{}, cfg *HTTPConfig) error {
 
 func decodeHTTPConfigFromString(s string, cfg *HTTPConfig) error {
 	if len(s) > 0 {
-		ss := os.ExpandEnv(s)
-		if err := json.Unmarshal([]byte(ss), &cfg); err != nil {
+		if err := json.Unmarshal([]byte(s), &cfg); err != nil {
 			return err
 		}
 	}
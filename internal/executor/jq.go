@@ -74,7 +74,7 @@ func CreateJqExecutor(ctx context.Context, step dag.Step) (Executor, error) {
 			return nil, err
 		}
 	}
-	s := os.ExpandEnv(step.Script)
+	s := step.ExpandEnv(step.Script)
 	input := map[string]interface{}{}
 	if err := json.Unmarshal([]byte(s), &input); err != nil {
 		return nil, err
@@ -63,10 +63,10 @@ func CreateMailExecutor(ctx context.Context, step dag.Step) (Executor, error) {
 		return nil, err
 	}
 
-	cfg.From = os.ExpandEnv(cfg.From)
-	cfg.To = os.ExpandEnv(cfg.To)
-	cfg.Subject = os.ExpandEnv(cfg.Subject)
-	cfg.Message = os.ExpandEnv(cfg.Message)
+	cfg.From = step.ExpandEnv(cfg.From)
+	cfg.To = step.ExpandEnv(cfg.To)
+	cfg.Subject = step.ExpandEnv(cfg.Subject)
+	cfg.Message = step.ExpandEnv(cfg.Message)
 
 	exec := &MailExecutor{cfg: &cfg}
 
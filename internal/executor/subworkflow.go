@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sync"
 	"syscall"
 
@@ -46,6 +47,19 @@ func (e *SubWorkflowExecutor) Kill(sig os.Signal) error {
 	return syscall.Kill(-e.cmd.Process.Pid, sig.(syscall.Signal))
 }
 
+// findSubWorkflow finds the sub workflow with the given name. A relative
+// name is looked up in the folder of the parent DAG first so that the DAGs
+// in a folder can refer to each other by their names.
+func findSubWorkflow(dagCtx dag.Context, name string) (*dag.DAG, error) {
+	if dagCtx.DAG != nil && dagCtx.DAG.Location != "" && !filepath.IsAbs(name) {
+		sibling := filepath.Join(filepath.Dir(dagCtx.DAG.Location), name)
+		if d, err := dagCtx.Finder.Find(sibling); err == nil {
+			return d, nil
+		}
+	}
+	return dagCtx.Finder.Find(name)
+}
+
 func CreateSubWorkflowExecutor(ctx context.Context, step dag.Step) (Executor, error) {
 	executable, err := os.Executable()
 	if err != nil {
@@ -57,12 +71,12 @@ func CreateSubWorkflowExecutor(ctx context.Context, step dag.Step) (Executor, er
 		return nil, fmt.Errorf("failed to get dag context: %w", err)
 	}
 
-	sugDAG, err := dagCtx.Finder.Find(step.SubWorkflow.Name)
+	sugDAG, err := findSubWorkflow(dagCtx, step.SubWorkflow.Name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find subworkflow %q: %w", step.SubWorkflow.Name, err)
 	}
 
-	params := os.ExpandEnv(step.SubWorkflow.Params)
+	params := step.ExpandEnv(step.SubWorkflow.Params)
 
 	args := []string{
 		"start",
@@ -1,7 +1,12 @@
 package client
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
+	"path"
+	"path/filepath"
 
 	"github.com/dagu-dev/dagu/internal/config"
 	"github.com/dagu-dev/dagu/internal/persistence"
@@ -56,3 +61,47 @@ func (f *dataStoreFactoryImpl) NewFlagStore() persistence.FlagStore {
 	s := storage.NewStorage(f.cfg.SuspendFlagsDir)
 	return local.NewFlagStore(s)
 }
+
+func (f *dataStoreFactoryImpl) NewSecretStore() persistence.SecretStore {
+	s := storage.NewStorage(path.Join(f.cfg.DataDir, "secrets"))
+	return local.NewSecretStore(s, f.secretKey)
+}
+
+func (f *dataStoreFactoryImpl) NewQueueStore() persistence.QueueStore {
+	s := storage.NewStorage(path.Join(f.cfg.DataDir, "queue"))
+	return local.NewQueueStore(s)
+}
+
+func (f *dataStoreFactoryImpl) NewTickStore() persistence.TickStore {
+	s := storage.NewStorage(path.Join(f.cfg.DataDir, "ticks"))
+	return local.NewTickStore(s)
+}
+
+// secretKey returns the key to encrypt the secrets.
+// If the key is not set, it is read from the key file.
+// The key file is created with a random key if it does not exist.
+func (f *dataStoreFactoryImpl) secretKey() ([]byte, error) {
+	if f.cfg.SecretKey != "" {
+		return []byte(f.cfg.SecretKey), nil
+	}
+	file := f.cfg.SecretKeyFile
+	key, err := os.ReadFile(file)
+	if err == nil {
+		return bytes.TrimSpace(key), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	key = []byte(hex.EncodeToString(buf))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
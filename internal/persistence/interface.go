@@ -1,6 +1,8 @@
 package persistence
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -14,6 +16,8 @@ var (
 	ErrRequestIdNotFound = fmt.Errorf("request id not found")
 	ErrNoStatusDataToday = fmt.Errorf("no status data today")
 	ErrNoStatusData      = fmt.Errorf("no status data")
+	ErrSecretNotFound    = fmt.Errorf("secret not found")
+	ErrSpecConflict      = fmt.Errorf("the DAG spec was modified by someone else")
 )
 
 type (
@@ -21,12 +25,18 @@ type (
 		NewHistoryStore() HistoryStore
 		NewDAGStore() DAGStore
 		NewFlagStore() FlagStore
+		NewSecretStore() SecretStore
+		NewQueueStore() QueueStore
+		NewTickStore() TickStore
 	}
 
 	HistoryStore interface {
 		Open(dagFile string, t time.Time, requestId string) error
 		Write(st *model.Status) error
 		Close() error
+		// Record writes the status of a run that was not run by an agent,
+		// such as a skipped run. Unlike Open, it is safe for concurrent use.
+		Record(dagFile string, t time.Time, st *model.Status) error
 		Update(dagFile, requestId string, st *model.Status) error
 		ReadStatusRecent(dagFile string, n int) []*model.StatusFile
 		ReadStatusToday(dagFile string) (*model.Status, error)
@@ -46,8 +56,14 @@ type (
 		Load(name string) (*dag.DAG, error)
 		Rename(oldName, newName string) error
 		GetSpec(name string) (string, error)
-		UpdateSpec(name string, spec []byte) error
+		// UpdateSpec overwrites the spec of the DAG. If the revision is not
+		// empty, it returns ErrSpecConflict unless the revision matches the
+		// current spec. The spec is not validated; the engine validates it
+		// before it is saved.
+		UpdateSpec(name string, spec []byte, revision string) error
 		Find(name string) (*dag.DAG, error)
+		// Location returns the path to the file of the DAG.
+		Location(name string) (string, error)
 	}
 
 	FlagStore interface {
@@ -55,6 +71,33 @@ type (
 		IsSuspended(id string) bool
 	}
 
+	SecretStore interface {
+		Set(name, value string) error
+		Get(name string) (string, error)
+		List() ([]string, error)
+		Delete(name string) error
+	}
+
+	// QueueStore stores the runs that wait in the run queue to be started.
+	QueueStore interface {
+		Enqueue(run *model.QueuedRun) error
+		// List returns the queued runs in the order they are started: the
+		// runs with a higher priority come first, and the runs with the same
+		// priority are in the order they were enqueued.
+		List() ([]*model.QueuedRun, error)
+		Remove(requestId string) error
+	}
+
+	// TickStore stores the last schedule time that the scheduler handled
+	// for each DAG, so that the runs missed while the scheduler was down
+	// can be caught up.
+	TickStore interface {
+		// GetLastTick returns the zero time if the DAG has no tick.
+		// The ticks are keyed by the location of the DAG file.
+		GetLastTick(dagFile string) (time.Time, error)
+		SetLastTick(dagFile string, t time.Time) error
+	}
+
 	GrepResult struct {
 		Name    string
 		DAG     *dag.DAG
@@ -74,7 +117,7 @@ type (
 
 func NewDAGStatus(d *dag.DAG, s *model.Status, suspended bool, err error) *DAGStatus {
 	ret := &DAGStatus{
-		File:      filepath.Base(d.Location),
+		File:      dag.RelPath(d.Location),
 		Dir:       filepath.Dir(d.Location),
 		DAG:       d,
 		Status:    s,
@@ -87,3 +130,10 @@ func NewDAGStatus(d *dag.DAG, s *model.Status, suspended bool, err error) *DAGSt
 	}
 	return ret
 }
+
+// SpecRevision returns the revision token of the DAG spec. The token changes
+// whenever the content of the spec changes.
+func SpecRevision(spec []byte) string {
+	sum := sha256.Sum256(spec)
+	return hex.EncodeToString(sum[:])
+}
@@ -21,11 +21,7 @@ import (
 	"github.com/dagu-dev/dagu/internal/persistence"
 	"github.com/dagu-dev/dagu/internal/persistence/model"
 
-<<<<<<< HEAD:internal/persistence/jsondb/database.go
-	"github.com/dagu-dev/dagu/internal/utils"
-=======
 	"github.com/dagu-dev/dagu/internal/util"
->>>>>>> 6b68bb3079fad58e733c7d7d9b827db71b3946ae:internal/persistence/jsondb/store.go
 )
 
 // Store is the interface to store dags status in local.
@@ -112,6 +108,20 @@ func (store *Store) Close() error {
 	return store.writer.close()
 }
 
+func (store *Store) Record(dagFile string, t time.Time, s *model.Status) error {
+	w, _, err := store.newWriter(dagFile, t, s.RequestId)
+	if err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = w.close()
+	}()
+	return w.write(s)
+}
+
 func ParseFile(file string) (*model.Status, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -176,10 +186,7 @@ func (store *Store) ReadStatusRecent(dagFile string, n int) []*model.StatusFile
 
 // ReadStatusToday returns a list of status files.
 func (store *Store) ReadStatusToday(dagFile string) (*model.Status, error) {
-<<<<<<< HEAD:internal/persistence/jsondb/database.go
-=======
 	// TODO: let's fix below not to use config here
->>>>>>> 6b68bb3079fad58e733c7d7d9b827db71b3946ae:internal/persistence/jsondb/store.go
 	readLatestStatus := config.Get().LatestStatusToday
 	file, err := store.latestToday(dagFile, time.Now(), readLatestStatus)
 	if err != nil {
@@ -341,15 +348,6 @@ func (store *Store) latestToday(dagFile string, day time.Time, latestStatusToday
 	pattern := ""
 	if latestStatusToday {
 		pattern = fmt.Sprintf("%s.%s*.*.dat", store.pattern(dagFile), day.Format("20060102"))
-<<<<<<< HEAD:internal/persistence/jsondb/database.go
-	} else {
-		pattern = fmt.Sprintf("%s.*.*.dat", store.pattern(dagFile))
-	}
-	matches, err := filepath.Glob(pattern)
-	if err == nil || len(matches) > 0 {
-		ret = filterLatest(matches, 1)
-=======
->>>>>>> 6b68bb3079fad58e733c7d7d9b827db71b3946ae:internal/persistence/jsondb/store.go
 	} else {
 		pattern = fmt.Sprintf("%s.*.*.dat", store.pattern(dagFile))
 	}
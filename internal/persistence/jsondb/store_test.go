@@ -215,6 +215,23 @@ func TestReadLatestStatus(t *testing.T) {
 
 }
 
+func TestRecord(t *testing.T) {
+	tmpDir, db := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	d := &dag.DAG{Name: "test_record", Location: "test_record.yaml"}
+	now := time.Now()
+	status := model.NewStatusSkipped(d, "request-id-1", now)
+	require.NoError(t, db.Record(d.Location, now, status))
+
+	ret, err := db.FindByRequestId(d.Location, "request-id-1")
+	require.NoError(t, err)
+	require.Equal(t, scheduler.StatusSkipped, ret.Status.Status)
+	require.Equal(t, util.FormatTime(now), ret.Status.StartedAt)
+}
+
 func TestReadStatusN(t *testing.T) {
 	tmpDir, db := setupTest(t)
 	defer func() {
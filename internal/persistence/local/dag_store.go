@@ -3,16 +3,18 @@ package local
 import (
 	"errors"
 	"fmt"
-	"github.com/dagu-dev/dagu/internal/persistence/filecache"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/internal/grep"
 	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/filecache"
 	"github.com/dagu-dev/dagu/internal/util"
 )
 
@@ -77,19 +79,29 @@ func (d *dagStoreImpl) GetSpec(name string) (string, error) {
 	return string(dat), nil
 }
 
-func (d *dagStoreImpl) UpdateSpec(name string, spec []byte) error {
-	// validation
-	_, err := dag.LoadYAML(spec)
-	if err != nil {
-		return err
-	}
+// specMu serializes the updates of the DAG specs so that the revision check
+// and the write are not interleaved with another update.
+var specMu sync.Mutex
+
+func (d *dagStoreImpl) UpdateSpec(name string, spec []byte, revision string) error {
 	loc, err := d.fileLocation(name)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errInvalidName, name)
 	}
+	specMu.Lock()
+	defer specMu.Unlock()
 	if !exists(loc) {
 		return fmt.Errorf("%w: %s", errDOGFileNotExist, loc)
 	}
+	if revision != "" {
+		cur, err := os.ReadFile(loc)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errFailedToReadDAGFile, err)
+		}
+		if persistence.SpecRevision(cur) != revision {
+			return fmt.Errorf("%w: %s", persistence.ErrSpecConflict, name)
+		}
+	}
 	err = os.WriteFile(loc, spec, 0755)
 	if err != nil {
 		return fmt.Errorf("%w: %s", errFailedToUpdateDAGFile, err)
@@ -98,6 +110,14 @@ func (d *dagStoreImpl) UpdateSpec(name string, spec []byte) error {
 	return nil
 }
 
+func (d *dagStoreImpl) Location(name string) (string, error) {
+	loc, err := d.fileLocation(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidName, name)
+	}
+	return loc, nil
+}
+
 func (d *dagStoreImpl) Create(name string, spec []byte) (string, error) {
 	if err := d.ensureDirExist(); err != nil {
 		return "", fmt.Errorf("%w: %s", errFailedToCreateDAGsDir, d.dir)
@@ -109,6 +129,10 @@ func (d *dagStoreImpl) Create(name string, spec []byte) (string, error) {
 	if exists(loc) {
 		return "", fmt.Errorf("%w: %s", errDAGFileAlreadyExists, loc)
 	}
+	// the DAG can be created in a folder of the DAGs directory
+	if err := os.MkdirAll(filepath.Dir(loc), 0755); err != nil {
+		return "", fmt.Errorf("%w: %s", errFailedToCreateDAGFile, err)
+	}
 	return name, os.WriteFile(loc, spec, 0644)
 }
 
@@ -130,12 +154,18 @@ func exists(file string) bool {
 	return !os.IsNotExist(err)
 }
 
+// fileLocation returns the location of the DAG file with the given name.
+// The name is the path of the file relative to the DAGs directory, such as
+// "team/etl", and it must not point outside of the directory.
 func (d *dagStoreImpl) fileLocation(name string) (string, error) {
-	if strings.Contains(name, "/") {
+	if filepath.IsAbs(name) || (strings.Contains(name, "/") && checkExtension(name) && util.FileExists(name)) {
 		// this is for backward compatibility
 		return name, nil
 	}
-	loc := path.Join(d.dir, name)
+	loc := filepath.Join(d.dir, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(d.dir, loc); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", errInvalidName, name)
+	}
 	return d.normalizeFilename(loc)
 }
 
@@ -159,24 +189,49 @@ func (d *dagStoreImpl) List() (ret []*dag.DAG, errs []string, err error) {
 		errs = append(errs, err.Error())
 		return
 	}
-	fis, err := os.ReadDir(d.dir)
+	files, err := d.files()
 	if err != nil {
 		errs = append(errs, err.Error())
 		return
 	}
-	for _, fi := range fis {
-		if checkExtension(fi.Name()) {
-			dat, err := d.GetMetadata(fi.Name())
-			if err == nil {
-				ret = append(ret, dat)
-			} else {
-				errs = append(errs, fmt.Sprintf("reading %s failed: %s", fi.Name(), err))
-			}
+	for _, file := range files {
+		dat, err := d.GetMetadata(file)
+		if err == nil {
+			ret = append(ret, dat)
+		} else {
+			errs = append(errs, fmt.Sprintf("reading %s failed: %s", file, err))
 		}
 	}
 	return ret, errs, nil
 }
 
+// files returns the DAG files in the DAGs directory and its folders
+// as the paths relative to the directory. Hidden folders are skipped.
+func (d *dagStoreImpl) files() ([]string, error) {
+	var ret []string
+	err := filepath.WalkDir(d.dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if p != d.dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !checkExtension(entry.Name()) || dag.IsBaseConfig(entry.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(d.dir, p)
+		if err != nil {
+			return err
+		}
+		ret = append(ret, filepath.ToSlash(rel))
+		return nil
+	})
+	return ret, err
+}
+
 var extensions = []string{".yaml", ".yml"}
 
 func checkExtension(file string) bool {
@@ -195,7 +250,7 @@ func (d *dagStoreImpl) Grep(pattern string) (ret []*persistence.GrepResult, errs
 		return
 	}
 
-	fis, err := os.ReadDir(d.dir)
+	files, err := d.files()
 	opts := &grep.Options{
 		IsRegexp: true,
 		Before:   2,
@@ -203,30 +258,28 @@ func (d *dagStoreImpl) Grep(pattern string) (ret []*persistence.GrepResult, errs
 	}
 
 	util.LogErr("read DAGs directory", err)
-	for _, fi := range fis {
-		if util.MatchExtension(fi.Name(), dag.EXTENSIONS) {
-			file := filepath.Join(d.dir, fi.Name())
-			dat, err := os.ReadFile(file)
-			if err != nil {
-				util.LogErr("read DAG file", err)
-				continue
-			}
-			m, err := grep.Grep(dat, fmt.Sprintf("(?i)%s", pattern), opts)
-			if err != nil {
-				errs = append(errs, fmt.Sprintf("grep %s failed: %s", fi.Name(), err))
-				continue
-			}
-			d, err := dag.LoadMetadata(file)
-			if err != nil {
-				errs = append(errs, fmt.Sprintf("check %s failed: %s", fi.Name(), err))
-				continue
-			}
-			ret = append(ret, &persistence.GrepResult{
-				Name:    strings.TrimSuffix(fi.Name(), path.Ext(fi.Name())),
-				DAG:     d,
-				Matches: m,
-			})
+	for _, name := range files {
+		file := filepath.Join(d.dir, filepath.FromSlash(name))
+		dat, err := os.ReadFile(file)
+		if err != nil {
+			util.LogErr("read DAG file", err)
+			continue
 		}
+		m, err := grep.Grep(dat, fmt.Sprintf("(?i)%s", pattern), opts)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("grep %s failed: %s", name, err))
+			continue
+		}
+		d, err := dag.LoadMetadata(file)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("check %s failed: %s", name, err))
+			continue
+		}
+		ret = append(ret, &persistence.GrepResult{
+			Name:    strings.TrimSuffix(name, path.Ext(name)),
+			DAG:     d,
+			Matches: m,
+		})
 	}
 	return ret, errs, nil
 }
@@ -245,6 +298,10 @@ func (d *dagStoreImpl) Rename(oldDAGPath, newDAGPath string) error {
 	if err != nil {
 		return fmt.Errorf("%w: %s", errInvalidNewName, newDAGPath)
 	}
+	// the DAG can be moved to another folder of the DAGs directory
+	if err := os.MkdirAll(filepath.Dir(newLoc), 0755); err != nil {
+		return err
+	}
 	return os.Rename(oldLoc, newLoc)
 }
 
@@ -258,15 +315,7 @@ func (d *dagStoreImpl) Find(name string) (*dag.DAG, error) {
 
 func (d *dagStoreImpl) resolve(name string) (string, error) {
 	// check if the name is a file path
-	if strings.Contains(name, string(filepath.Separator)) {
-		if !util.FileExists(name) {
-			return "", fmt.Errorf("workflow %s not found", name)
-		}
-		return name, nil
-	}
-
-	// check if the name is a file path
-	if strings.Contains(name, string(filepath.Separator)) {
+	if filepath.IsAbs(name) {
 		foundPath, err := find(name)
 		if err != nil {
 			return "", fmt.Errorf("workflow %s not found", name)
@@ -275,6 +324,7 @@ func (d *dagStoreImpl) resolve(name string) (string, error) {
 	}
 
 	// find the DAG definition
+	// the name can contain the folders of the DAG such as "team/etl"
 	for _, dir := range []string{".", d.dir} {
 		subWorkflowPath := filepath.Join(dir, name)
 		foundPath, err := find(subWorkflowPath)
@@ -0,0 +1,71 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDAGStoreFolders(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := config.Get()
+	dagsDir := cfg.DAGs
+	cfg.DAGs = tmpDir
+	defer func() {
+		cfg.DAGs = dagsDir
+	}()
+
+	spec := []byte("steps:\n  - name: a\n    command: \"true\"\n")
+	ds := NewDAGStore(tmpDir)
+
+	_, err := ds.Create("top", spec)
+	require.NoError(t, err)
+	_, err = ds.Create("team/etl", spec)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(tmpDir, "team", "etl.yaml"))
+
+	// the base configurations and the hidden folders are not DAGs
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "team", "base.yaml"), []byte("env:\n  - A: a\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".hidden"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".hidden", "hidden.yaml"), spec, 0600))
+
+	t.Run("List", func(t *testing.T) {
+		dags, errs, err := ds.List()
+		require.NoError(t, err)
+		require.Empty(t, errs)
+		require.Len(t, dags, 2)
+
+		groups := map[string]string{}
+		for _, d := range dags {
+			groups[d.Name] = d.Group
+		}
+		require.Equal(t, map[string]string{"top": "", "etl": "team"}, groups)
+	})
+	t.Run("Grep", func(t *testing.T) {
+		ret, _, err := ds.Grep("true")
+		require.NoError(t, err)
+		var names []string
+		for _, r := range ret {
+			names = append(names, r.Name)
+		}
+		require.ElementsMatch(t, []string{"top", "team/etl"}, names)
+	})
+	t.Run("Find", func(t *testing.T) {
+		d, err := ds.Find("team/etl")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(tmpDir, "team", "etl.yaml"), d.Location)
+	})
+	t.Run("Rename", func(t *testing.T) {
+		require.NoError(t, ds.Rename("top", "other/top"))
+		require.FileExists(t, filepath.Join(tmpDir, "other", "top.yaml"))
+	})
+	t.Run("InvalidName", func(t *testing.T) {
+		_, err := ds.Create("../outside", spec)
+		require.Error(t, err)
+		_, err = ds.GetSpec("team/../../outside")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,89 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/local/storage"
+	"github.com/dagu-dev/dagu/internal/persistence/model"
+)
+
+// queuedRunExt is the extension of the files that store the queued runs.
+const queuedRunExt = ".json"
+
+// queueStoreImpl stores each queued run in a file. The name of the file
+// starts with the time the run was enqueued so that the files are listed
+// in the order they were enqueued.
+type queueStoreImpl struct {
+	storage *storage.Storage
+}
+
+func NewQueueStore(s *storage.Storage) persistence.QueueStore {
+	return &queueStoreImpl{
+		storage: s,
+	}
+}
+
+func (q *queueStoreImpl) Enqueue(run *model.QueuedRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	file := fmt.Sprintf("%020d_%s%s", time.Now().UnixNano(), run.RequestId, queuedRunExt)
+	// The file is written under a temporary name so that the other processes
+	// never read a partially written file.
+	tmp := file + ".tmp"
+	if err := q.storage.Write(tmp, data); err != nil {
+		return err
+	}
+	return q.storage.Rename(tmp, file)
+}
+
+func (q *queueStoreImpl) List() ([]*model.QueuedRun, error) {
+	files, err := q.storage.List()
+	if err != nil {
+		return nil, err
+	}
+	// The files are sorted by the name, which is the order they were enqueued.
+	ret := []*model.QueuedRun{}
+	for _, file := range files {
+		if !strings.HasSuffix(file, queuedRunExt) {
+			continue
+		}
+		data, err := q.storage.Read(file)
+		if os.IsNotExist(err) {
+			// The run was removed by another process.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		run := &model.QueuedRun{}
+		if err := json.Unmarshal(data, run); err != nil {
+			return nil, fmt.Errorf("invalid queued run %s: %w", file, err)
+		}
+		ret = append(ret, run)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Priority > ret[j].Priority
+	})
+	return ret, nil
+}
+
+func (q *queueStoreImpl) Remove(requestId string) error {
+	files, err := q.storage.List()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_"+requestId+queuedRunExt) {
+			return q.storage.Delete(file)
+		}
+	}
+	return fmt.Errorf("%w: %s", persistence.ErrRequestIdNotFound, requestId)
+}
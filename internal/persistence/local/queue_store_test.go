@@ -0,0 +1,53 @@
+package local
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/local/storage"
+	"github.com/dagu-dev/dagu/internal/persistence/model"
+
+	"github.com/dagu-dev/dagu/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueStore(t *testing.T) {
+	tmpDir := util.MustTempDir("test-queue-store")
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	qs := NewQueueStore(storage.NewStorage(tmpDir))
+
+	runs, err := qs.List()
+	require.NoError(t, err)
+	require.Empty(t, runs)
+
+	for _, run := range []*model.QueuedRun{
+		{RequestId: "a", Name: "low1"},
+		{RequestId: "b", Name: "high1", Priority: 10},
+		{RequestId: "c", Name: "low2"},
+		{RequestId: "d", Name: "high2", Priority: 10},
+	} {
+		require.NoError(t, qs.Enqueue(run))
+	}
+
+	// higher priority first, FIFO within the same priority
+	runs, err = qs.List()
+	require.NoError(t, err)
+	var names []string
+	for _, run := range runs {
+		names = append(names, run.Name)
+	}
+	require.Equal(t, []string{"high1", "high2", "low1", "low2"}, names)
+
+	require.NoError(t, qs.Remove("b"))
+	runs, err = qs.List()
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	require.Equal(t, "high2", runs[0].Name)
+
+	err = qs.Remove("b")
+	require.ErrorIs(t, err, persistence.ErrRequestIdNotFound)
+}
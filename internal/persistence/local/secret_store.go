@@ -0,0 +1,123 @@
+package local
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/local/storage"
+)
+
+// secretExt is the extension of the files that store the secrets.
+const secretExt = ".secret"
+
+var (
+	errInvalidSecretName = errors.New("secret name must consist of letters, digits and underscores")
+	errInvalidSecretData = errors.New("failed to decrypt the secret")
+)
+
+var secretNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretStoreImpl stores each secret in a file encrypted with AES-GCM.
+// The key is loaded when a secret is read or written for the first time.
+type secretStoreImpl struct {
+	storage *storage.Storage
+	keyFunc func() ([]byte, error)
+}
+
+func NewSecretStore(s *storage.Storage, keyFunc func() ([]byte, error)) persistence.SecretStore {
+	return &secretStoreImpl{
+		storage: s,
+		keyFunc: keyFunc,
+	}
+}
+
+func (s *secretStoreImpl) Set(name, value string) error {
+	if !secretNameRegex.MatchString(name) {
+		return fmt.Errorf("%w: %q", errInvalidSecretName, name)
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return s.storage.Write(name+secretExt, gcm.Seal(nonce, nonce, []byte(value), []byte(name)))
+}
+
+func (s *secretStoreImpl) Get(name string) (string, error) {
+	if !secretNameRegex.MatchString(name) {
+		return "", fmt.Errorf("%w: %q", errInvalidSecretName, name)
+	}
+	data, err := s.storage.Read(name + secretExt)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %s", persistence.ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: %s", errInvalidSecretData, name)
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	value, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidSecretData, name)
+	}
+	return string(value), nil
+}
+
+func (s *secretStoreImpl) List() ([]string, error) {
+	files, err := s.storage.List()
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, f := range files {
+		if name, ok := strings.CutSuffix(f, secretExt); ok {
+			ret = append(ret, name)
+		}
+	}
+	slices.Sort(ret)
+	return ret, nil
+}
+
+func (s *secretStoreImpl) Delete(name string) error {
+	if !secretNameRegex.MatchString(name) {
+		return fmt.Errorf("%w: %q", errInvalidSecretName, name)
+	}
+	if !s.storage.Exists(name + secretExt) {
+		return fmt.Errorf("%w: %s", persistence.ErrSecretNotFound, name)
+	}
+	return s.storage.Delete(name + secretExt)
+}
+
+// cipher returns the AES-GCM cipher with the key of the store.
+// The key is hashed so that a key of any length can be used.
+func (s *secretStoreImpl) cipher() (cipher.AEAD, error) {
+	key, err := s.keyFunc()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(key)
+	block, err := aes.NewCipher(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
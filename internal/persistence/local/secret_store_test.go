@@ -0,0 +1,57 @@
+package local
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/local/storage"
+	"github.com/dagu-dev/dagu/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretStore(t *testing.T) {
+	tmpDir := util.MustTempDir("test-secret-store")
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	key := func() ([]byte, error) { return []byte("test-key"), nil }
+	ss := NewSecretStore(storage.NewStorage(tmpDir), key)
+
+	err := ss.Set("DB_PASSWORD", "s3cret")
+	require.NoError(t, err)
+
+	// the value is not stored in plain text
+	data, err := os.ReadFile(path.Join(tmpDir, "DB_PASSWORD.secret"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "s3cret")
+
+	value, err := ss.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", value)
+
+	names, err := ss.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"DB_PASSWORD"}, names)
+
+	// the value cannot be decrypted with another key
+	other := NewSecretStore(storage.NewStorage(tmpDir), func() ([]byte, error) {
+		return []byte("other-key"), nil
+	})
+	_, err = other.Get("DB_PASSWORD")
+	require.ErrorIs(t, err, errInvalidSecretData)
+
+	err = ss.Delete("DB_PASSWORD")
+	require.NoError(t, err)
+
+	_, err = ss.Get("DB_PASSWORD")
+	require.ErrorIs(t, err, persistence.ErrSecretNotFound)
+
+	err = ss.Delete("DB_PASSWORD")
+	require.ErrorIs(t, err, persistence.ErrSecretNotFound)
+
+	err = ss.Set("../invalid", "value")
+	require.ErrorIs(t, err, errInvalidSecretName)
+}
@@ -5,7 +5,7 @@ import (
 	"path"
 )
 
-// Storage is a storage for flags.
+// Storage is a storage for flags and secrets.
 type Storage struct {
 	Dir string
 }
@@ -34,3 +34,34 @@ func (s *Storage) Exists(file string) bool {
 func (s *Storage) Delete(file string) error {
 	return os.Remove(path.Join(s.Dir, file))
 }
+
+// Write writes the data to the given file.
+// The file is readable only by the owner.
+func (s *Storage) Write(file string, data []byte) error {
+	return os.WriteFile(path.Join(s.Dir, file), data, 0600)
+}
+
+// Read reads the data from the given file.
+func (s *Storage) Read(file string) ([]byte, error) {
+	return os.ReadFile(path.Join(s.Dir, file))
+}
+
+// Rename renames the given file.
+func (s *Storage) Rename(oldFile, newFile string) error {
+	return os.Rename(path.Join(s.Dir, oldFile), path.Join(s.Dir, newFile))
+}
+
+// List returns the names of the files in the storage.
+func (s *Storage) List() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			ret = append(ret, e.Name())
+		}
+	}
+	return ret, nil
+}
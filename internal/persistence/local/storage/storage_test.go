@@ -31,3 +31,21 @@ func TestStorage(t *testing.T) {
 	exist = s.Exists(f)
 	require.False(t, exist)
 }
+
+func TestStorage_ReadWrite(t *testing.T) {
+	tmpDir := util.MustTempDir("test-storage-rw")
+	defer os.RemoveAll(tmpDir)
+
+	s := NewStorage(tmpDir)
+
+	err := s.Write("a.secret", []byte("data"))
+	require.NoError(t, err)
+
+	data, err := s.Read("a.secret")
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+
+	files, err := s.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.secret"}, files)
+}
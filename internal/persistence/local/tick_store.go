@@ -0,0 +1,61 @@
+package local
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dagu-dev/dagu/internal/persistence"
+	"github.com/dagu-dev/dagu/internal/persistence/local/storage"
+)
+
+// tickStoreImpl stores the last tick of each DAG in a file.
+type tickStoreImpl struct {
+	storage *storage.Storage
+}
+
+func NewTickStore(s *storage.Storage) persistence.TickStore {
+	return &tickStoreImpl{
+		storage: s,
+	}
+}
+
+func (ts *tickStoreImpl) GetLastTick(dagFile string) (time.Time, error) {
+	data, err := ts.storage.Read(tickFileName(dagFile))
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last tick of %s: %w", dagFile, err)
+	}
+	return t, nil
+}
+
+func (ts *tickStoreImpl) SetLastTick(dagFile string, t time.Time) error {
+	file := tickFileName(dagFile)
+	// The file is written under a temporary name so that the tick is never
+	// lost by a partially written file.
+	tmp := file + ".tmp"
+	if err := ts.storage.Write(tmp, []byte(t.Format(time.RFC3339))); err != nil {
+		return err
+	}
+	return ts.storage.Rename(tmp, file)
+}
+
+// tickFileName returns the name of the file for the DAG file. It has the
+// hash of the path, so the DAGs with the same name in different folders
+// have their own ticks.
+func tickFileName(dagFile string) string {
+	h := md5.New()
+	_, _ = h.Write([]byte(dagFile))
+	name := strings.TrimSuffix(filepath.Base(dagFile), filepath.Ext(dagFile))
+	return fmt.Sprintf("%s-%s.tick", normalizeFilename(name, "-"), hex.EncodeToString(h.Sum(nil)))
+}
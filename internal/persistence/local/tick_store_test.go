@@ -0,0 +1,38 @@
+package local
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dagu-dev/dagu/internal/persistence/local/storage"
+
+	"github.com/dagu-dev/dagu/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickStore(t *testing.T) {
+	tmpDir := util.MustTempDir("test-tick-store")
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	ts := NewTickStore(storage.NewStorage(tmpDir))
+
+	tick, err := ts.GetLastTick("/dags/test.yaml")
+	require.NoError(t, err)
+	require.True(t, tick.IsZero())
+
+	now := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+	require.NoError(t, ts.SetLastTick("/dags/test.yaml", now))
+	require.NoError(t, ts.SetLastTick("/dags/test.yaml", now.Add(time.Hour)))
+
+	tick, err = ts.GetLastTick("/dags/test.yaml")
+	require.NoError(t, err)
+	require.True(t, now.Add(time.Hour).Equal(tick))
+
+	// the DAG with the same name in another folder has its own tick
+	tick, err = ts.GetLastTick("/dags/team/test.yaml")
+	require.NoError(t, err)
+	require.True(t, tick.IsZero())
+}
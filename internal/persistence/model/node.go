@@ -3,6 +3,7 @@ package model
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/internal/scheduler"
@@ -14,43 +15,72 @@ var (
 )
 
 type Node struct {
-	dag.Step   `json:"Step"`
-	Log        string               `json:"Log"`
-	StartedAt  string               `json:"StartedAt"`
-	FinishedAt string               `json:"FinishedAt"`
-	Status     scheduler.NodeStatus `json:"Status"`
-	RetryCount int                  `json:"RetryCount"`
-	DoneCount  int                  `json:"DoneCount"`
-	Error      string               `json:"Error"`
-	StatusText string               `json:"StatusText"`
+	dag.Step     `json:"Step"`
+	Log          string               `json:"Log"`
+	StartedAt    string               `json:"StartedAt"`
+	FinishedAt   string               `json:"FinishedAt"`
+	Status       scheduler.NodeStatus `json:"Status"`
+	RetryCount   int                  `json:"RetryCount"`
+	NextRetryAt  string               `json:"NextRetryAt,omitempty"`
+	DoneCount    int                  `json:"DoneCount"`
+	Error        string               `json:"Error"`
+	StatusText   string               `json:"StatusText"`
+	TimedOut     bool                 `json:"TimedOut,omitempty"`
+	Outputs      map[string]string    `json:"Outputs,omitempty"`
+	PoolWaitTime string               `json:"PoolWaitTime,omitempty"`
 }
 
 func (n *Node) ToNode() *scheduler.Node {
 	startedAt, _ := util.ParseTime(n.StartedAt)
 	finishedAt, _ := util.ParseTime(n.FinishedAt)
+	var nextRetryAt time.Time
+	if n.NextRetryAt != "" {
+		nextRetryAt, _ = util.ParseTime(n.NextRetryAt)
+	}
+	var poolWaitTime time.Duration
+	if n.PoolWaitTime != "" {
+		poolWaitTime, _ = time.ParseDuration(n.PoolWaitTime)
+	}
 	return scheduler.NewNode(n.Step, scheduler.NodeState{
-		Status:     n.Status,
-		Log:        n.Log,
-		StartedAt:  startedAt,
-		FinishedAt: finishedAt,
-		RetryCount: n.RetryCount,
-		DoneCount:  n.DoneCount,
-		Error:      errFromText(n.Error),
+		Status:       n.Status,
+		Log:          n.Log,
+		StartedAt:    startedAt,
+		FinishedAt:   finishedAt,
+		RetryCount:   n.RetryCount,
+		NextRetryAt:  nextRetryAt,
+		DoneCount:    n.DoneCount,
+		Error:        errFromText(n.Error),
+		TimedOut:     n.TimedOut,
+		Outputs:      n.Outputs,
+		PoolWaitTime: poolWaitTime,
 	})
 }
 
 func FromNode(n scheduler.NodeState, step dag.Step) *Node {
 	return &Node{
-		Step:       step,
-		Log:        n.Log,
-		StartedAt:  util.FormatTime(n.StartedAt),
-		FinishedAt: util.FormatTime(n.FinishedAt),
-		Status:     n.Status,
-		StatusText: n.Status.String(),
-		RetryCount: n.RetryCount,
-		DoneCount:  n.DoneCount,
-		Error:      errText(n.Error),
+		Step:         step,
+		Log:          n.Log,
+		StartedAt:    util.FormatTime(n.StartedAt),
+		FinishedAt:   util.FormatTime(n.FinishedAt),
+		Status:       n.Status,
+		StatusText:   n.Status.String(),
+		RetryCount:   n.RetryCount,
+		NextRetryAt:  formatTime(&n.NextRetryAt),
+		DoneCount:    n.DoneCount,
+		Error:        errText(n.Error),
+		TimedOut:     n.TimedOut,
+		Outputs:      n.Outputs,
+		PoolWaitTime: formatDuration(n.PoolWaitTime),
+	}
+}
+
+// formatDuration returns the duration such as "1m30s", or an empty string if it is zero.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	if d <= 0 {
+		return ""
 	}
+	return d.String()
 }
 
 func errFromText(err string) error {
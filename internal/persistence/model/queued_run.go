@@ -0,0 +1,13 @@
+package model
+
+// QueuedRun is a run of a DAG that waits in the run queue to be started.
+type QueuedRun struct {
+	RequestId string `json:"RequestId"`
+	DAGFile   string `json:"DAGFile"`
+	Name      string `json:"Name"`
+	Params    string `json:"Params"`
+	Priority  int    `json:"Priority"`
+	QueuedAt  string `json:"QueuedAt"`
+	// ScheduledAt is the schedule time of a scheduled run.
+	ScheduledAt string `json:"ScheduledAt,omitempty"`
+}
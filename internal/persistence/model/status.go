@@ -46,7 +46,11 @@ type Status struct {
 	FinishedAt string           `json:"FinishedAt"`
 	Log        string           `json:"Log"`
 	Params     string           `json:"Params"`
-	mu         sync.RWMutex
+	TimedOut   bool             `json:"TimedOut,omitempty"`
+	// OutputVariables are the values of the output variables captured by
+	// the steps. They are restored when the execution is retried.
+	OutputVariables map[string]string `json:"OutputVariables,omitempty"`
+	mu              sync.RWMutex
 }
 
 type StatusFile struct {
@@ -67,6 +71,22 @@ func NewStatusDefault(d *dag.DAG) *Status {
 	return NewStatus(d, nil, scheduler.StatusNone, int(PidNotRunning), nil, nil)
 }
 
+// NewStatusQueued returns the status of a run of the DAG that waits in the run queue.
+func NewStatusQueued(d *dag.DAG, run *QueuedRun) *Status {
+	ret := NewStatus(d, nil, scheduler.StatusQueued, int(PidNotRunning), nil, nil)
+	ret.RequestId = run.RequestId
+	ret.Params = run.Params
+	return ret
+}
+
+// NewStatusSkipped returns the status of a scheduled run of the DAG that
+// was skipped because the DAG was running.
+func NewStatusSkipped(d *dag.DAG, requestId string, scheduledAt time.Time) *Status {
+	ret := NewStatus(d, nil, scheduler.StatusSkipped, int(PidNotRunning), &scheduledAt, &scheduledAt)
+	ret.RequestId = requestId
+	return ret
+}
+
 func Time(t time.Time) *time.Time {
 	return &t
 }
@@ -72,3 +72,15 @@ func TestJsonMarshal(t *testing.T) {
 	}
 	t.Logf(string(js))
 }
+
+func TestNodeOutputs(t *testing.T) {
+	outputs := map[string]string{"count": "3"}
+	node := FromNode(scheduler.NodeState{Outputs: outputs}, dag.Step{Name: "extract"})
+
+	js, err := json.Marshal(node)
+	require.NoError(t, err)
+
+	var node_ Node
+	require.NoError(t, json.Unmarshal(js, &node_))
+	require.Equal(t, outputs, node_.ToNode().State().Outputs)
+}
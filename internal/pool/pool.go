@@ -0,0 +1,69 @@
+package pool
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+)
+
+// Pool is a named resource pool with a fixed number of slots. The pool is
+// shared by all the processes that use the same directory. Each slot is a
+// lock file, and a slot is acquired by locking the file. The lock is
+// released by the OS when the process exits, so a slot is never leaked by
+// a process that did not exit normally.
+type Pool struct {
+	Name  string
+	Slots int
+	dir   string
+}
+
+// Pools are the resource pools by name. The names are case-insensitive.
+type Pools map[string]*Pool
+
+// New creates the pools with the number of slots by name in the directory.
+func New(dir string, slots map[string]int) Pools {
+	ret := Pools{}
+	for name, n := range slots {
+		key := strings.ToLower(name)
+		ret[key] = &Pool{
+			Name:  name,
+			Slots: n,
+			dir:   path.Join(dir, key),
+		}
+	}
+	return ret
+}
+
+// Get returns the pool with the name.
+func (p Pools) Get(name string) (*Pool, bool) {
+	ret, ok := p[strings.ToLower(name)]
+	return ret, ok
+}
+
+// TryAcquire acquires a free slot of the pool without waiting. It returns
+// the function to release the slot, or nil if all the slots are in use.
+func (p *Pool) TryAcquire() (func(), error) {
+	if p.Slots <= 0 {
+		return nil, fmt.Errorf("pool %q has no slots", p.Name)
+	}
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, err
+	}
+	for i := 0; i < p.Slots; i++ {
+		f, err := os.OpenFile(path.Join(p.dir, fmt.Sprintf("slot-%d.lock", i)), os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			_ = f.Close()
+			continue
+		}
+		return func() {
+			// closing the file releases the lock
+			_ = f.Close()
+		}, nil
+	}
+	return nil, nil
+}
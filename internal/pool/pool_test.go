@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dagu-dev/dagu/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	tmpDir := util.MustTempDir("test-pool")
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	pools := New(tmpDir, map[string]int{"warehouse": 2})
+
+	_, ok := pools.Get("unknown")
+	require.False(t, ok)
+
+	p, ok := pools.Get("Warehouse")
+	require.True(t, ok)
+
+	release1, err := p.TryAcquire()
+	require.NoError(t, err)
+	require.NotNil(t, release1)
+
+	release2, err := p.TryAcquire()
+	require.NoError(t, err)
+	require.NotNil(t, release2)
+
+	// all the slots are in use
+	release3, err := p.TryAcquire()
+	require.NoError(t, err)
+	require.Nil(t, release3)
+
+	// the slots are shared by the pools created from the same directory
+	// regardless of the case of the name
+	other, _ := New(tmpDir, map[string]int{"WAREHOUSE": 2}).Get("warehouse")
+	release3, err = other.TryAcquire()
+	require.NoError(t, err)
+	require.Nil(t, release3)
+
+	release1()
+	release3, err = other.TryAcquire()
+	require.NoError(t, err)
+	require.NotNil(t, release3)
+
+	release2()
+	release3()
+}
+
+func TestPool_NoSlots(t *testing.T) {
+	tmpDir := util.MustTempDir("test-pool")
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	p, _ := New(tmpDir, map[string]int{"empty": 0}).Get("empty")
+	_, err := p.TryAcquire()
+	require.Error(t, err)
+}
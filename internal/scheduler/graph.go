@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +28,7 @@ type ExecutionGraph struct {
 var (
 	errCycleDetected = errors.New("cycle detected")
 	errStepNotFound  = errors.New("step not found")
+	errDuplicateStep = errors.New("duplicate step name")
 )
 
 // NewExecutionGraph creates a new execution graph with the given steps.
@@ -70,12 +73,7 @@ func NewExecutionGraphForRetry(nodes ...*Node) (*ExecutionGraph, error) {
 				if !ok {
 					return false
 				}
-
-				graph.outputVariables.Store(key, value)
-				err := os.Setenv(k, v[len(key.(string))+1:])
-				if err != nil {
-					log.Printf("set env error : %s", err.Error())
-				}
+				graph.restoreOutputVariable(k, v[len(k)+1:])
 				return true
 			})
 		}
@@ -93,6 +91,35 @@ func NewExecutionGraphForRetry(nodes ...*Node) (*ExecutionGraph, error) {
 	return graph, nil
 }
 
+// OutputVariables returns the values of the output variables captured by
+// the steps so that they can be restored when the execution is retried.
+func (g *ExecutionGraph) OutputVariables() map[string]string {
+	ret := map[string]string{}
+	g.outputVariables.Range(func(key, value any) bool {
+		k, ok := key.(string)
+		if !ok {
+			return true
+		}
+		if v, ok := value.(string); ok {
+			ret[k] = strings.TrimPrefix(v, k+"=")
+		}
+		return true
+	})
+	return ret
+}
+
+// RestoreOutputVariables sets the output variables captured by the steps
+// of the previous execution so that the steps to be retried can use them.
+func (g *ExecutionGraph) RestoreOutputVariables(vars map[string]string) {
+	for k, v := range vars {
+		g.restoreOutputVariable(k, v)
+	}
+}
+
+func (g *ExecutionGraph) restoreOutputVariable(key, value string) {
+	g.outputVariables.Store(key, fmt.Sprintf("%s=%s", key, value))
+}
+
 // Duration returns the duration of the execution.
 func (g *ExecutionGraph) Duration() time.Duration {
 	g.mu.RLock()
@@ -124,7 +151,7 @@ func (g *ExecutionGraph) StartAt() time.Time {
 func (g *ExecutionGraph) IsRunning() bool {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	for _, node := range g.Nodes() {
+	for _, node := range g.nodes {
 		if node.State().Status == NodeStatusRunning {
 			return true
 		}
@@ -152,10 +179,95 @@ func (g *ExecutionGraph) Start() {
 
 // Nodes returns the nodes of the execution graph.
 func (g *ExecutionGraph) Nodes() []*Node {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	return g.nodes
 }
 
+// addSteps adds the steps generated by the node at runtime. Steps without
+// dependencies depend on the node, and the nodes that depend on the node
+// wait for the new nodes as well. Nothing is added if the steps have an
+// unknown dependency or make a cycle.
+func (g *ExecutionGraph) addSteps(node *Node, steps []dag.Step) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := map[string]bool{}
+	for _, step := range steps {
+		if _, err := g.findStep(step.Name); err == nil || names[step.Name] {
+			return fmt.Errorf("%w: %s", errDuplicateStep, step.Name)
+		}
+		names[step.Name] = true
+	}
+
+	// keep the current state to roll back
+	var (
+		nodes = g.nodes
+		from  = maps.Clone(g.from)
+		to    = maps.Clone(g.to)
+		added []*Node
+	)
+	rollback := func() {
+		for _, n := range added {
+			delete(g.dict, n.id)
+		}
+		g.nodes, g.from, g.to = nodes, from, to
+	}
+
+	downstream := g.from[node.id]
+	for _, step := range steps {
+		if len(step.Depends) == 0 {
+			step.Depends = []string{node.step.Name}
+		}
+		if step.Dir == "" {
+			step.Dir = node.step.Dir
+		}
+		step.GeneratedBy = node.step.Name
+		step.OutputVariables = g.outputVariables
+		n := &Node{step: step}
+		n.init()
+		g.dict[n.id] = n
+		g.nodes = append(slices.Clip(g.nodes), n)
+		added = append(added, n)
+	}
+	for _, n := range added {
+		for _, dep := range n.step.Depends {
+			depNode, err := g.findStep(dep)
+			if err != nil {
+				rollback()
+				return err
+			}
+			g.addEdge(depNode, n)
+		}
+		for _, id := range downstream {
+			g.addEdge(n, g.dict[id])
+		}
+	}
+	if g.hasCycle() {
+		rollback()
+		return errCycleDetected
+	}
+
+	var addedNames []string
+	for _, n := range added {
+		addedNames = append(addedNames, n.step.Name)
+	}
+	for _, id := range downstream {
+		g.dict[id].addDepends(addedNames...)
+	}
+	return nil
+}
+
+// dependencies returns the ids of the nodes that the node depends on.
+func (g *ExecutionGraph) dependencies(id int) []int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.to[id]
+}
+
 func (g *ExecutionGraph) node(id int) *Node {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	return g.dict[id]
 }
 
@@ -189,9 +301,40 @@ func (g *ExecutionGraph) setupRetry() error {
 		}
 		frontier = next
 	}
+
+	// the steps generated by a step to be retried are generated again
+	var generated []*Node
+	for _, node := range g.nodes {
+		if node.step.GeneratedBy == "" {
+			continue
+		}
+		if gen, err := g.findStep(node.step.GeneratedBy); err == nil && retry[gen.id] {
+			generated = append(generated, node)
+		}
+	}
+	for _, node := range generated {
+		log.Printf("remove generated node: %s", node.step.Name)
+		g.removeNode(node)
+	}
 	return nil
 }
 
+// removeNode removes the node and its edges from the graph.
+func (g *ExecutionGraph) removeNode(node *Node) {
+	isNode := func(id int) bool { return id == node.id }
+	for _, id := range g.to[node.id] {
+		g.from[id] = slices.DeleteFunc(slices.Clone(g.from[id]), isNode)
+	}
+	for _, id := range g.from[node.id] {
+		g.to[id] = slices.DeleteFunc(slices.Clone(g.to[id]), isNode)
+		g.dict[id].removeDepends(node.step.Name)
+	}
+	delete(g.from, node.id)
+	delete(g.to, node.id)
+	delete(g.dict, node.id)
+	g.nodes = slices.DeleteFunc(slices.Clone(g.nodes), func(n *Node) bool { return n == node })
+}
+
 func (g *ExecutionGraph) setup() error {
 	for _, node := range g.nodes {
 		for _, dep := range node.step.Depends {
@@ -251,6 +394,18 @@ func (g *ExecutionGraph) addEdge(from, to *Node) {
 	g.to[to.id] = append(g.to[to.id], from.id)
 }
 
+// stepOutputs returns the outputs of the step with the given name.
+func (g *ExecutionGraph) stepOutputs(name string) map[string]string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for _, n := range g.dict {
+		if n.step.Name == name {
+			return n.State().Outputs
+		}
+	}
+	return nil
+}
+
 func (g *ExecutionGraph) findStep(name string) (*Node, error) {
 	for _, n := range g.dict {
 		if n.step.Name == name {
@@ -85,3 +85,90 @@ func TestRetryExecution(t *testing.T) {
 	require.Equal(t, NodeStatusNone, nodes[6].State().Status)
 	require.Equal(t, NodeStatusSkipped, nodes[7].State().Status)
 }
+
+func TestAddSteps(t *testing.T) {
+	t.Run("AddSteps", func(t *testing.T) {
+		g, err := NewExecutionGraph(
+			dag.Step{Name: "1", Dir: "/tmp"},
+			dag.Step{Name: "2", Depends: []string{"1"}},
+		)
+		require.NoError(t, err)
+
+		gen := g.Nodes()[0]
+		err = g.addSteps(gen, []dag.Step{
+			{Name: "a"},
+			{Name: "b", Depends: []string{"a"}},
+		})
+		require.NoError(t, err)
+
+		nodes := g.Nodes()
+		require.Len(t, nodes, 4)
+		require.Equal(t, []string{"1"}, nodes[2].Step().Depends)
+		require.Equal(t, "/tmp", nodes[2].Step().Dir)
+		require.Equal(t, "1", nodes[2].Step().GeneratedBy)
+		require.Equal(t, []string{"a"}, nodes[3].Step().Depends)
+		require.Equal(t, []string{"1", "a", "b"}, nodes[1].Step().Depends)
+		require.ElementsMatch(t,
+			[]int{gen.id, nodes[2].id, nodes[3].id}, g.dependencies(nodes[1].id))
+	})
+	for name, tc := range map[string]struct {
+		steps []dag.Step
+		err   error
+	}{
+		"Cycle":          {[]dag.Step{{Name: "a", Depends: []string{"2"}}}, errCycleDetected},
+		"UnknownDepends": {[]dag.Step{{Name: "a", Depends: []string{"x"}}}, errStepNotFound},
+		"DuplicateName":  {[]dag.Step{{Name: "2"}}, errDuplicateStep},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g, err := NewExecutionGraph(
+				dag.Step{Name: "1"},
+				dag.Step{Name: "2", Depends: []string{"1"}},
+			)
+			require.NoError(t, err)
+
+			err = g.addSteps(g.Nodes()[0], tc.steps)
+			require.ErrorIs(t, err, tc.err)
+
+			// the graph is not changed
+			require.Len(t, g.Nodes(), 2)
+			require.Equal(t, []string{"1"}, g.Nodes()[1].Step().Depends)
+			require.Len(t, g.dependencies(g.Nodes()[1].id), 1)
+			require.False(t, g.hasCycle())
+		})
+	}
+}
+
+func TestRetryGeneratedSteps(t *testing.T) {
+	nodes := []*Node{
+		{
+			step:      dag.Step{Name: "1", Command: "true", GenerateSteps: true},
+			NodeState: NodeState{Status: NodeStatusError},
+		},
+		{
+			step:      dag.Step{Name: "2", Command: "true", Depends: []string{"1", "a"}},
+			NodeState: NodeState{Status: NodeStatusCancel},
+		},
+		{
+			step:      dag.Step{Name: "a", Command: "true", Depends: []string{"1"}, GeneratedBy: "1"},
+			NodeState: NodeState{Status: NodeStatusSuccess},
+		},
+		{
+			step:      dag.Step{Name: "3", Command: "true", GenerateSteps: true},
+			NodeState: NodeState{Status: NodeStatusSuccess},
+		},
+		{
+			step:      dag.Step{Name: "b", Command: "true", Depends: []string{"3"}, GeneratedBy: "3"},
+			NodeState: NodeState{Status: NodeStatusSuccess},
+		},
+	}
+	g, err := NewExecutionGraphForRetry(nodes...)
+	require.NoError(t, err)
+
+	var names []string
+	for _, n := range g.Nodes() {
+		names = append(names, n.Step().Name)
+	}
+	require.Equal(t, []string{"1", "2", "3", "b"}, names)
+	require.Equal(t, []string{"1"}, nodes[1].Step().Depends)
+	require.Equal(t, NodeStatusSuccess, nodes[4].State().Status)
+}
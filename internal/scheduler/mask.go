@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/dag"
+)
+
+// maskedValue is written in place of the secret values.
+const maskedValue = "*******"
+
+// maxMaskBufferSize is the size of the buffered output at which the output
+// is written even though the line is not finished yet.
+const maxMaskBufferSize = 64 * 1024
+
+// maskingWriter replaces the secret values in the output with maskedValue.
+// The output is written line by line so that a secret value split across
+// two writes is masked as well. Flush must be called to write the rest of
+// the output.
+type maskingWriter struct {
+	w        io.Writer
+	replacer *strings.Replacer
+	buf      []byte
+}
+
+// newSecretReplacer returns a replacer that masks the secret values.
+func newSecretReplacer(secrets []string) *strings.Replacer {
+	var oldnew []string
+	for _, s := range secrets {
+		oldnew = append(oldnew, s, maskedValue)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// MaskSecrets replaces the secret values in the command and the output
+// variables of the step with the masked value. The command is expanded
+// when the step runs, so it may contain the secret values.
+func MaskSecrets(step dag.Step, secrets []string) dag.Step {
+	if len(secrets) == 0 {
+		return step
+	}
+	r := newSecretReplacer(secrets)
+	step.CmdWithArgs = r.Replace(step.CmdWithArgs)
+	step.Command = r.Replace(step.Command)
+	args := make([]string, len(step.Args))
+	for i, arg := range step.Args {
+		args[i] = r.Replace(arg)
+	}
+	step.Args = args
+	if step.OutputVariables != nil {
+		vars := &dag.SyncMap{}
+		step.OutputVariables.Range(func(key, value any) bool {
+			if v, ok := value.(string); ok {
+				value = r.Replace(v)
+			}
+			vars.Store(key, value)
+			return true
+		})
+		step.OutputVariables = vars
+	}
+	return step
+}
+
+// MaskState replaces the secret values in the outputs of the node state
+// with the masked value.
+func MaskState(state NodeState, secrets []string) NodeState {
+	state.Outputs = MaskOutputs(state.Outputs, secrets)
+	return state
+}
+
+// MaskOutputs returns a copy of the outputs with the secret values
+// replaced with the masked value.
+func MaskOutputs(outputs map[string]string, secrets []string) map[string]string {
+	if len(secrets) == 0 || outputs == nil {
+		return outputs
+	}
+	r := newSecretReplacer(secrets)
+	ret := make(map[string]string, len(outputs))
+	for k, v := range outputs {
+		ret[k] = r.Replace(v)
+	}
+	return ret
+}
+
+// newMaskingWriter returns a writer that masks the secret values.
+// It returns the writer as it is if there are no secret values.
+func newMaskingWriter(w io.Writer, secrets []string) io.Writer {
+	if w == nil || len(secrets) == 0 {
+		return w
+	}
+	return &maskingWriter{w: w, replacer: newSecretReplacer(secrets)}
+}
+
+func (m *maskingWriter) Write(p []byte) (int, error) {
+	m.buf = append(m.buf, p...)
+	i := bytes.LastIndexByte(m.buf, '\n')
+	if i < 0 && len(m.buf) < maxMaskBufferSize {
+		return len(p), nil
+	}
+	if i < 0 {
+		i = len(m.buf) - 1
+	}
+	if _, err := io.WriteString(m.w, m.replacer.Replace(string(m.buf[:i+1]))); err != nil {
+		return 0, err
+	}
+	m.buf = append(m.buf[:0], m.buf[i+1:]...)
+	return len(p), nil
+}
+
+// Flush writes the rest of the output.
+func (m *maskingWriter) Flush() error {
+	if len(m.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(m.w, m.replacer.Replace(string(m.buf)))
+	m.buf = m.buf[:0]
+	return err
+}
@@ -4,13 +4,19 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"math/rand"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/dagu-dev/dagu/internal/dag"
@@ -28,6 +34,7 @@ const (
 	NodeStatusCancel
 	NodeStatusSuccess
 	NodeStatusSkipped
+	NodeStatusWaiting
 )
 
 func (s NodeStatus) String() string {
@@ -42,6 +49,8 @@ func (s NodeStatus) String() string {
 		return "finished"
 	case NodeStatusSkipped:
 		return "skipped"
+	case NodeStatusWaiting:
+		return "waiting"
 	case NodeStatusNone:
 		fallthrough
 	default:
@@ -49,6 +58,16 @@ func (s NodeStatus) String() string {
 	}
 }
 
+var (
+	errStepTimedOut       = errors.New("step timed out")
+	errRepeatLimitReached = errors.New("repeat limit reached before the repeat condition was met")
+	errWaitForTimedOut    = errors.New("timed out waiting for the preconditions")
+)
+
+// timeoutGracePeriod is the time to wait after sending the stop signal
+// to a timed out step before the step is forcibly killed.
+var timeoutGracePeriod = time.Second * 5
+
 func NewNode(step dag.Step, state NodeState) *Node {
 	return &Node{step: step, NodeState: state}
 }
@@ -71,20 +90,32 @@ type Node struct {
 	stderrWriter *bufio.Writer
 	outputWriter *os.File
 	outputReader *os.File
+	stepsOutput  *bytes.Buffer
 	scriptFile   *os.File
+	outputsFile  string
+	secrets      []string
+	maskers      []*maskingWriter
 	done         bool
+	// conditionsMet is true when the preconditions of the waiting node are met.
+	conditionsMet bool
 }
 
 // NodeState is the state of a node.
 type NodeState struct {
-	Status     NodeStatus
-	Log        string
-	StartedAt  time.Time
-	FinishedAt time.Time
-	RetryCount int
-	RetriedAt  time.Time
-	DoneCount  int
-	Error      error
+	Status      NodeStatus
+	Log         string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	RetryCount  int
+	RetriedAt   time.Time
+	NextRetryAt time.Time
+	DoneCount   int
+	Error       error
+	ExitCode    int
+	TimedOut    bool
+	Outputs     map[string]string
+	// PoolWaitTime is the total time the node waited for a slot of the pool.
+	PoolWaitTime time.Duration
 }
 
 func (n *Node) finish() {
@@ -111,20 +142,105 @@ func (n *Node) Execute(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	n.setTimedOut(false)
+	if n.step.Timeout > 0 {
+		stop := n.watchTimeout(ctx)
+		defer stop()
+	}
 	n.SetError(cmd.Run())
+	n.setExitCode(exitCode(n.Error))
+	if n.isTimedOut() {
+		n.SetError(fmt.Errorf("%w after %s", errStepTimedOut, n.step.Timeout))
+	}
 	if n.outputReader != nil && n.step.Output != "" {
 		util.LogErr("close pipe writer", n.outputWriter.Close())
 		var buf bytes.Buffer
 		// TODO: Error handling
 		_, _ = io.Copy(&buf, n.outputReader)
 		ret := strings.TrimSpace(buf.String())
-		_ = os.Setenv(n.step.Output, ret)
 		n.step.OutputVariables.Store(n.step.Output, fmt.Sprintf("%s=%s", n.step.Output, ret))
 	}
+	if err := n.readOutputs(); err != nil && n.Error == nil {
+		n.SetError(err)
+	}
 
 	return n.Error
 }
 
+// watchTimeout stops the running command when it exceeds the step timeout.
+// It sends SignalOnStop (SIGTERM by default) first, and if the command is
+// still running after the grace period, it cancels the executor context and
+// sends SIGKILL. The returned function must be called when the command exits.
+func (n *Node) watchTimeout(ctx context.Context) func() {
+	done := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(n.step.Timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		log.Printf("%s timed out after %s", n.step.Name, n.step.Timeout)
+		n.setTimedOut(true)
+		n.kill(n.stopSignal())
+
+		grace := time.NewTimer(timeoutGracePeriod)
+		defer grace.Stop()
+		select {
+		case <-done:
+		case <-grace.C:
+			log.Printf("%s did not exit within %s, killing it", n.step.Name, timeoutGracePeriod)
+			n.mu.RLock()
+			if n.cancelFunc != nil {
+				n.cancelFunc()
+			}
+			n.mu.RUnlock()
+			n.kill(syscall.SIGKILL)
+		}
+	}()
+	return func() { close(done) }
+}
+
+// stopSignal returns the signal to send when the step needs to be stopped.
+func (n *Node) stopSignal() os.Signal {
+	if n.step.SignalOnStop != "" {
+		return unix.SignalNum(n.step.SignalOnStop)
+	}
+	return syscall.SIGTERM
+}
+
+// kill sends the signal to the running command without changing the node status.
+func (n *Node) kill(sig os.Signal) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.cmd != nil {
+		log.Printf("Sending %s signal to %s", sig, n.step.Name)
+		util.LogErr("sending signal", n.cmd.Kill(sig))
+	}
+}
+
+func (n *Node) addPoolWaitTime(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.PoolWaitTime += d
+}
+
+func (n *Node) setTimedOut(timedOut bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.TimedOut = timedOut
+}
+
+func (n *Node) isTimedOut() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.TimedOut
+}
+
 func (n *Node) setupExec(ctx context.Context) (executor.Executor, error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -134,7 +250,7 @@ func (n *Node) setupExec(ctx context.Context) (executor.Executor, error) {
 	n.cancelFunc = fn
 
 	if n.step.CmdWithArgs != "" {
-		n.step.Command, n.step.Args = util.SplitCommand(n.step.CmdWithArgs, true)
+		n.step.Command, n.step.Args = util.SplitCommandWithEnv(n.step.CmdWithArgs, n.step.ExpandEnv)
 	}
 
 	if n.scriptFile != nil {
@@ -143,7 +259,16 @@ func (n *Node) setupExec(ctx context.Context) (executor.Executor, error) {
 		n.step.Args = append(args, n.scriptFile.Name())
 	}
 
-	cmd, err := executor.CreateExecutor(ctx, n.step)
+	step := n.step
+	if n.outputsFile != "" {
+		// the outputs of the previous execution are discarded when the step is repeated
+		if err := os.Remove(n.outputsFile); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		step.Variables = append(slices.Clone(step.Variables), fmt.Sprintf("%s=%s", OutputEnv, n.outputsFile))
+	}
+
+	cmd, err := executor.CreateExecutor(ctx, step)
 	if err != nil {
 		return nil, err
 	}
@@ -151,13 +276,21 @@ func (n *Node) setupExec(ctx context.Context) (executor.Executor, error) {
 
 	var stdout io.Writer
 
-	if n.logWriter != nil {
-		stdout = n.logWriter
+	// the secret values are masked in the log, stdout and stderr files
+	for _, m := range n.maskers {
+		// flush the output of the previous execution when the step is repeated
+		_ = m.Flush()
+	}
+	n.maskers = nil
+	logWriter := n.mask(n.logWriter)
+
+	if logWriter != nil {
+		stdout = logWriter
 		cmd.SetStderr(stdout)
 	}
 
 	if n.stdoutWriter != nil {
-		stdout = io.MultiWriter(n.logWriter, n.stdoutWriter)
+		stdout = io.MultiWriter(logWriter, n.mask(n.stdoutWriter))
 	}
 
 	if n.step.Output != "" {
@@ -168,9 +301,15 @@ func (n *Node) setupExec(ctx context.Context) (executor.Executor, error) {
 		stdout = io.MultiWriter(stdout, n.outputWriter)
 	}
 
+	if n.step.GenerateSteps {
+		// the output is parsed as the step definitions after the command exits
+		n.stepsOutput = &bytes.Buffer{}
+		stdout = io.MultiWriter(stdout, n.stepsOutput)
+	}
+
 	cmd.SetStdout(stdout)
 	if n.stderrWriter != nil {
-		cmd.SetStderr(n.stderrWriter)
+		cmd.SetStderr(n.mask(n.stderrWriter))
 	} else {
 		cmd.SetStderr(stdout)
 	}
@@ -178,18 +317,193 @@ func (n *Node) setupExec(ctx context.Context) (executor.Executor, error) {
 	return cmd, nil
 }
 
+// mask returns a writer that masks the secret values in the output.
+func (n *Node) mask(w *bufio.Writer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	if m, ok := newMaskingWriter(w, n.secrets).(*maskingWriter); ok {
+		n.maskers = append(n.maskers, m)
+		return m
+	}
+	return w
+}
+
+// setSecrets sets the secret values to be masked in the output.
+func (n *Node) setSecrets(secrets []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.secrets = secrets
+}
+
+// readOutputs reads the outputs that the step wrote to the outputs file.
+func (n *Node) readOutputs() error {
+	if n.outputsFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(n.outputsFile)
+	if os.IsNotExist(err) {
+		// the step has no outputs
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	outputs, err := parseOutputs(data)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.Outputs = outputs
+	return nil
+}
+
+// expandOutputRefs replaces the references to the outputs of other steps
+// in the step. It must be called before the node is set up so that the
+// script is written with the outputs.
+func (n *Node) expandOutputRefs(lookup func(step string) map[string]string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var lastErr error
+	expand := func(s string) string {
+		ret, err := expandOutputRefs(s, lookup)
+		if err != nil {
+			lastErr = err
+		}
+		return ret
+	}
+	n.step.CmdWithArgs = expand(n.step.CmdWithArgs)
+	n.step.Command = expand(n.step.Command)
+	args := make([]string, len(n.step.Args))
+	for i, arg := range n.step.Args {
+		args[i] = expand(arg)
+	}
+	n.step.Args = args
+	n.step.Script = expand(n.step.Script)
+	n.step.Dir = expand(n.step.Dir)
+	n.step.Stdout = expand(n.step.Stdout)
+	n.step.Stderr = expand(n.step.Stderr)
+	if n.step.SubWorkflow != nil {
+		subWorkflow := *n.step.SubWorkflow
+		subWorkflow.Params = expand(subWorkflow.Params)
+		n.step.SubWorkflow = &subWorkflow
+	}
+	return lastErr
+}
+
 func (n *Node) Step() dag.Step {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 	return n.step
 }
 
+// exitCode returns the exit code of the command from the error returned by the executor.
+// It returns 0 if the command succeeded and -1 if the exit code is unknown.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func (n *Node) setExitCode(code int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.ExitCode = code
+}
+
+// shouldRetry returns true if the failed step should be retried
+// according to the retry policy.
+func (n *Node) shouldRetry() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	policy := n.step.RetryPolicy
+	if policy == nil || policy.Limit <= n.RetryCount {
+		return false
+	}
+	if len(policy.ExitCodes) > 0 && !slices.Contains(policy.ExitCodes, n.ExitCode) {
+		log.Printf("%s exited with code %d which is not retryable", n.step.Name, n.ExitCode)
+		return false
+	}
+	return true
+}
+
+// retryInterval returns the time to wait before the next retry.
+// The interval is multiplied by the backoff for each retry, capped by
+// the max interval, and then a random jitter is added.
+func (n *Node) retryInterval() time.Duration {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	policy := n.step.RetryPolicy
+	interval := policy.Interval
+	if policy.Backoff > 1 && n.RetryCount > 1 {
+		interval = time.Duration(float64(interval) * math.Pow(policy.Backoff, float64(n.RetryCount-1)))
+	}
+	if policy.MaxInterval > 0 && interval > policy.MaxInterval {
+		interval = policy.MaxInterval
+	}
+	if policy.Jitter > 0 {
+		interval += time.Duration(rand.Int63n(int64(policy.Jitter)))
+	}
+	return interval
+}
+
+// repeatsOnExitCode returns true if the last run exited with one of
+// the exit codes that make the step repeat.
+func (n *Node) repeatsOnExitCode() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return slices.Contains(n.step.RepeatPolicy.ExitCode, n.ExitCode)
+}
+
+// isRepeatFinished returns true if the repeating step should stop repeating.
+// A conditional repeat finishes when the condition is met or the step exits
+// with an exit code other than the repeat exit codes. It returns an error if
+// the limit is reached before that happens.
+func (n *Node) isRepeatFinished() (bool, error) {
+	policy := n.step.RepeatPolicy
+	if policy.Condition != nil {
+		err := dag.EvalStepConditions(&n.step, []*dag.Condition{policy.Condition})
+		if err == nil {
+			return true, nil
+		}
+		log.Printf("%s will repeat: %s", n.step.Name, err)
+	}
+	if len(policy.ExitCode) > 0 && !n.repeatsOnExitCode() {
+		return true, nil
+	}
+	if policy.Limit > 0 && n.getDoneCount() >= policy.Limit {
+		if policy.IsConditional() {
+			return true, fmt.Errorf("%w: %d runs", errRepeatLimitReached, policy.Limit)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (n *Node) setNextRetryAt(nextRetryAt time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.NextRetryAt = nextRetryAt
+}
+
 func (n *Node) getRetryCount() int {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 	return n.RetryCount
 }
 
+func (n *Node) setStartedAt(startedAt time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.StartedAt = startedAt
+}
+
 func (n *Node) setRetriedAt(retriedAt time.Time) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -208,6 +522,34 @@ func (n *Node) getDoneCount() int {
 	return n.DoneCount
 }
 
+// generatedSteps returns the standard output of the last run
+// of a step that generates steps.
+func (n *Node) generatedSteps() []byte {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.stepsOutput == nil {
+		return nil
+	}
+	return n.stepsOutput.Bytes()
+}
+
+// addDepends adds the dependencies to the step so that they are saved
+// with the status when the graph is expanded at runtime.
+func (n *Node) addDepends(names ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.step.Depends = append(slices.Clone(n.step.Depends), names...)
+}
+
+// removeDepends removes the dependency from the step.
+func (n *Node) removeDepends(name string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.step.Depends = slices.DeleteFunc(slices.Clone(n.step.Depends), func(d string) bool {
+		return d == name
+	})
+}
+
 func (n *Node) clearState() {
 	n.NodeState = NodeState{}
 }
@@ -218,6 +560,18 @@ func (n *Node) setStatus(status NodeStatus) {
 	n.Status = status
 }
 
+func (n *Node) setConditionsMet(met bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.conditionsMet = met
+}
+
+func (n *Node) isConditionsMet() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.conditionsMet
+}
+
 func (n *Node) setErr(err error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -229,6 +583,10 @@ func (n *Node) signal(sig os.Signal, allowOverride bool) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	status := n.Status
+	if status == NodeStatusWaiting {
+		n.Status = NodeStatusCancel
+		return
+	}
 	if status == NodeStatusRunning && n.cmd != nil {
 		sigsig := sig
 		if allowOverride && n.step.SignalOnStop != "" {
@@ -246,7 +604,7 @@ func (n *Node) cancel() {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	status := n.Status
-	if status == NodeStatusRunning {
+	if status == NodeStatusRunning || status == NodeStatusWaiting {
 		n.Status = NodeStatusCancel
 	}
 	if n.cancelFunc != nil {
@@ -270,6 +628,7 @@ func (n *Node) setup(logDir string, requestId string) error {
 		n.setupStdout,
 		n.setupStderr,
 		n.setupScript,
+		n.setupOutputs,
 	} {
 		if err := fn(); err != nil {
 			n.Error = err
@@ -296,6 +655,14 @@ func (n *Node) setupScript() (err error) {
 	return err
 }
 
+// setupOutputs sets the path of the file that the step writes its outputs
+// to. It is next to the log file, and it is created by the step only when
+// the step has outputs.
+func (n *Node) setupOutputs() error {
+	n.outputsFile = strings.TrimSuffix(n.Log, ".log") + ".outputs"
+	return nil
+}
+
 func (n *Node) setupStdout() error {
 	if n.step.Stdout != "" {
 		f := n.step.Stdout
@@ -353,14 +720,19 @@ func (n *Node) teardown() error {
 	n.logLock.Lock()
 	n.done = true
 	var lastErr error
-	for _, w := range []*bufio.Writer{n.logWriter, n.stdoutWriter} {
+	for _, m := range n.maskers {
+		if err := m.Flush(); err != nil {
+			lastErr = err
+		}
+	}
+	for _, w := range []*bufio.Writer{n.logWriter, n.stdoutWriter, n.stderrWriter} {
 		if w != nil {
 			if err := w.Flush(); err != nil {
 				lastErr = err
 			}
 		}
 	}
-	for _, f := range []*os.File{n.logFile, n.stdoutFile} {
+	for _, f := range []*os.File{n.logFile, n.stdoutFile, n.stderrFile} {
 		if f != nil {
 			if err := f.Sync(); err != nil {
 				lastErr = err
@@ -373,6 +745,9 @@ func (n *Node) teardown() error {
 	if n.scriptFile != nil {
 		_ = os.Remove(n.scriptFile.Name())
 	}
+	if n.outputsFile != "" {
+		_ = os.Remove(n.outputsFile)
+	}
 	if lastErr != nil {
 		n.Error = lastErr
 	}
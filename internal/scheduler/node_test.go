@@ -76,6 +76,93 @@ func TestSignalSpecified(t *testing.T) {
 	require.Equal(t, n.State().Status, NodeStatusCancel)
 }
 
+func TestTimeout(t *testing.T) {
+	n := &Node{
+		step: dag.Step{
+			Command:         "sleep",
+			Args:            []string{"100"},
+			OutputVariables: &dag.SyncMap{},
+			Timeout:         time.Millisecond * 100,
+		}}
+
+	n.setStatus(NodeStatusRunning)
+	err := n.Execute(context.Background())
+
+	require.ErrorIs(t, err, errStepTimedOut)
+	require.True(t, n.State().TimedOut)
+	require.Equal(t, NodeStatusRunning, n.State().Status)
+}
+
+func TestTimeoutKill(t *testing.T) {
+	origGracePeriod := timeoutGracePeriod
+	timeoutGracePeriod = time.Millisecond * 100
+	defer func() {
+		timeoutGracePeriod = origGracePeriod
+	}()
+
+	n := &Node{
+		step: dag.Step{
+			Command:         "sh",
+			Args:            []string{"-c", "trap '' TERM; sleep 100"},
+			OutputVariables: &dag.SyncMap{},
+			Timeout:         time.Millisecond * 100,
+		}}
+
+	n.setStatus(NodeStatusRunning)
+	err := n.Execute(context.Background())
+
+	require.ErrorIs(t, err, errStepTimedOut)
+	require.True(t, n.State().TimedOut)
+}
+
+func TestRetryInterval(t *testing.T) {
+	n := &Node{
+		step: dag.Step{
+			RetryPolicy: &dag.RetryPolicy{
+				Limit:       5,
+				Interval:    time.Second,
+				Backoff:     2,
+				MaxInterval: time.Second * 5,
+			},
+		}}
+
+	for _, expected := range []time.Duration{
+		time.Second, time.Second * 2, time.Second * 4, time.Second * 5,
+	} {
+		n.incRetryCount()
+		require.Equal(t, expected, n.retryInterval())
+	}
+
+	n.step.RetryPolicy.Jitter = time.Second
+	interval := n.retryInterval()
+	require.GreaterOrEqual(t, interval, time.Second*5)
+	require.Less(t, interval, time.Second*6)
+}
+
+func TestShouldRetry(t *testing.T) {
+	n := &Node{
+		step: dag.Step{
+			Command:         "sh",
+			Args:            []string{"-c", "exit 75"},
+			OutputVariables: &dag.SyncMap{},
+			RetryPolicy: &dag.RetryPolicy{
+				Limit:     1,
+				ExitCodes: []int{75},
+			},
+		}}
+
+	require.Error(t, n.Execute(context.Background()))
+	require.Equal(t, 75, n.State().ExitCode)
+	require.True(t, n.shouldRetry())
+
+	n.step.RetryPolicy.ExitCodes = []int{1}
+	require.False(t, n.shouldRetry())
+
+	n.step.RetryPolicy.ExitCodes = nil
+	n.incRetryCount()
+	require.False(t, n.shouldRetry())
+}
+
 func TestLog(t *testing.T) {
 	n := &Node{
 		step: dag.Step{
@@ -136,6 +223,104 @@ echo Stderr message >&2
 	require.Equal(t, "Stdout message\n", string(dat))
 }
 
+func TestMaskSecrets(t *testing.T) {
+	n := &Node{
+		step: dag.Step{
+			Command: "sh",
+			Script: `
+echo "password is s3cret" >&1
+printf "token is tok" >&2
+printf "en-123" >&2
+echo "token=token-123" > "$DAGU_OUTPUT"
+			`,
+			Dir:             os.Getenv("HOME"),
+			Stdout:          "test-mask-stdout.log",
+			Stderr:          "test-mask-stderr.log",
+			Output:          "MASK_TEST",
+			OutputVariables: &dag.SyncMap{},
+		},
+	}
+	n.setSecrets([]string{"s3cret", "token-123"})
+
+	runTestNode(t, n)
+
+	dat, _ := os.ReadFile(n.logFile.Name())
+	require.Equal(t, "password is *******\n", string(dat))
+
+	dat, _ = os.ReadFile(path.Join(os.Getenv("HOME"), n.step.Stdout))
+	require.Equal(t, "password is *******\n", string(dat))
+
+	dat, _ = os.ReadFile(path.Join(os.Getenv("HOME"), n.step.Stderr))
+	require.Equal(t, "token is *******", string(dat))
+
+	// the output variable and the outputs are masked in the persisted status
+	secrets := []string{"s3cret", "token-123"}
+	v, _ := MaskSecrets(n.Step(), secrets).OutputVariables.Load("MASK_TEST")
+	require.Equal(t, "MASK_TEST=password is *******", v)
+	require.Equal(t, map[string]string{"token": "*******"}, MaskState(n.State(), secrets).Outputs)
+	require.Equal(t, map[string]string{"MASK_TEST": "password is *******"}, MaskOutputs(map[string]string{"MASK_TEST": "password is s3cret"}, secrets))
+}
+
+func TestParseOutputs(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "Empty", data: "\n"},
+		{name: "KeyValue", data: "a=1\nb=x=y\n\n", want: map[string]string{"a": "1", "b": "x=y"}},
+		{name: "JSON", data: `{"a": "1", "b": [1, 2], "c": true}`, want: map[string]string{"a": "1", "b": "[1, 2]", "c": "true"}},
+		{name: "InvalidLine", data: "a=1\nb", wantErr: true},
+		{name: "InvalidJSON", data: `{"a": `, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseOutputs([]byte(tc.data))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestExpandOutputRefs(t *testing.T) {
+	outputs := map[string]map[string]string{
+		"extract": {
+			"count":  "3",
+			"result": `{"items": [{"name": "a"}, {"name": "b", "tags": ["x"]}]}`,
+		},
+	}
+	lookup := func(step string) map[string]string { return outputs[step] }
+	for _, tc := range []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "NoRef", value: "echo $count", want: "echo $count"},
+		{name: "Value", value: "echo ${steps.extract.outputs.count}", want: "echo 3"},
+		{name: "JSONPath", value: "${steps.extract.outputs.result.items[1].name}", want: "b"},
+		{name: "JSONValue", value: "${steps.extract.outputs.result.items[1].tags}", want: `["x"]`},
+		{name: "NotFound", value: "${steps.extract.outputs.unknown}", wantErr: errOutputNotFound},
+		{name: "UnknownStep", value: "${steps.other.outputs.count}", wantErr: errOutputNotFound},
+		{name: "OutOfRange", value: "${steps.extract.outputs.result.items[2]}", wantErr: errInvalidJSONPath},
+		{name: "NotJSON", value: "${steps.extract.outputs.count.value}", wantErr: errInvalidJSONPath},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandOutputRefs(tc.value, lookup)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestNode(t *testing.T) {
 	n := &Node{
 		step: dag.Step{
@@ -160,11 +345,13 @@ func TestNode(t *testing.T) {
 }
 
 func TestOutput(t *testing.T) {
+	// the output variables are shared by the steps of the graph
+	vars := &dag.SyncMap{}
 	n := &Node{
 		step: dag.Step{
 			CmdWithArgs:     "echo hello",
 			Output:          "OUTPUT_TEST",
-			OutputVariables: &dag.SyncMap{},
+			OutputVariables: vars,
 		},
 	}
 	err := n.setup(os.Getenv("HOME"), "test-request-id-output")
@@ -177,19 +364,21 @@ func TestOutput(t *testing.T) {
 
 	dat, _ := os.ReadFile(n.logFile.Name())
 	require.Equal(t, "hello\n", string(dat))
-	require.Equal(t, "hello", os.ExpandEnv("$OUTPUT_TEST"))
+	require.Equal(t, "hello", n.step.ExpandEnv("$OUTPUT_TEST"))
+	// the output is not set in the environment of the process
+	require.Empty(t, os.Getenv("OUTPUT_TEST"))
 
 	// Use the previous output in the subsequent step
 	n2 := &Node{
 		step: dag.Step{
 			CmdWithArgs:     "echo $OUTPUT_TEST",
 			Output:          "OUTPUT_TEST2",
-			OutputVariables: &dag.SyncMap{},
+			OutputVariables: vars,
 		},
 	}
 
 	runTestNode(t, n2)
-	require.Equal(t, "hello", os.ExpandEnv("$OUTPUT_TEST2"))
+	require.Equal(t, "hello", n2.step.ExpandEnv("$OUTPUT_TEST2"))
 
 	// Use the previous output in the subsequent step inside a script
 	n3 := &Node{
@@ -197,12 +386,12 @@ func TestOutput(t *testing.T) {
 			Command:         "sh",
 			Script:          "echo $OUTPUT_TEST2",
 			Output:          "OUTPUT_TEST3",
-			OutputVariables: &dag.SyncMap{},
+			OutputVariables: vars,
 		},
 	}
 
 	runTestNode(t, n3)
-	require.Equal(t, "hello", os.ExpandEnv("$OUTPUT_TEST3"))
+	require.Equal(t, "hello", n3.step.ExpandEnv("$OUTPUT_TEST3"))
 }
 
 func TestOutputJson(t *testing.T) {
@@ -242,7 +431,7 @@ func TestOutputJson(t *testing.T) {
 
 			v, _ := n.step.OutputVariables.Load("OUTPUT_JSON_TEST")
 			require.Equal(t, fmt.Sprintf("OUTPUT_JSON_TEST=%s", test.Want), v)
-			require.Equal(t, test.Want, os.ExpandEnv("$OUTPUT_JSON_TEST"))
+			require.Equal(t, test.Want, n.step.ExpandEnv("$OUTPUT_JSON_TEST"))
 		})
 	}
 }
@@ -304,7 +493,7 @@ func TestOutputSpecialchar(t *testing.T) {
 
 			v, _ := n.step.OutputVariables.Load("OUTPUT_SPECIALCHAR_TEST")
 			require.Equal(t, fmt.Sprintf("OUTPUT_SPECIALCHAR_TEST=%s", test.Want), v)
-			require.Equal(t, test.Want, os.ExpandEnv("$OUTPUT_SPECIALCHAR_TEST"))
+			require.Equal(t, test.Want, n.step.ExpandEnv("$OUTPUT_SPECIALCHAR_TEST"))
 		})
 	}
 }
@@ -336,10 +525,28 @@ func TestRunScript(t *testing.T) {
 	err = n.teardown()
 	require.NoError(t, err)
 
-	require.Equal(t, "hello", os.Getenv("SCRIPT_TEST"))
+	require.Equal(t, "hello", n.step.ExpandEnv("$SCRIPT_TEST"))
 	require.NoFileExists(t, n.scriptFile.Name())
 }
 
+func TestOutputsFile(t *testing.T) {
+	n := &Node{
+		step: dag.Step{
+			CmdWithArgs:     "true",
+			OutputVariables: &dag.SyncMap{},
+		},
+	}
+	err := n.setup(os.Getenv("HOME"), fmt.Sprintf("test-request-id-%d", rand.Int()))
+	require.NoError(t, err)
+	require.NoError(t, n.Execute(context.Background()))
+
+	// the file is not created for a step without outputs
+	require.NotEmpty(t, n.outputsFile)
+	require.NoFileExists(t, n.outputsFile)
+	require.Empty(t, n.State().Outputs)
+	require.NoError(t, n.teardown())
+}
+
 func TestTeardown(t *testing.T) {
 	n := &Node{
 		step: dag.Step{
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dagu-dev/dagu/internal/dag"
+)
+
+// OutputEnv is the environment variable that contains the path to the file
+// that a step writes its named outputs to.
+const OutputEnv = "DAGU_OUTPUT"
+
+var (
+	errInvalidOutputs  = errors.New("invalid outputs: each line must be in the form of key=value")
+	errOutputNotFound  = errors.New("output not found")
+	errInvalidJSONPath = errors.New("invalid JSON path")
+)
+
+// outputRefRegex matches a reference to an output of a step such as
+// "${steps.extract.outputs.count}" or "${steps.extract.outputs.result.items[0].name}".
+var outputRefRegex = regexp.MustCompile(`\$\{steps\.(.+?)\.outputs\.([A-Za-z0-9_-]+)((?:\.[A-Za-z0-9_-]+|\[\d+\])*)\}`)
+
+// jsonPathRegex matches an element of a JSON path such as ".name" or "[0]".
+var jsonPathRegex = regexp.MustCompile(`\.([A-Za-z0-9_-]+)|\[(\d+)\]`)
+
+// parseOutputs parses the outputs written by a step.
+// The outputs are either a JSON object or lines in the form of key=value.
+// The values of a JSON object that are not strings are kept as JSON.
+func parseOutputs(data []byte) (map[string]string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	ret := map[string]string{}
+	if data[0] == '{' {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, fmt.Errorf("invalid outputs: %w", err)
+		}
+		for k, v := range obj {
+			var s string
+			if err := json.Unmarshal(v, &s); err == nil {
+				ret[k] = s
+			} else {
+				ret[k] = string(v)
+			}
+		}
+		return ret, nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%w: %q", errInvalidOutputs, line)
+		}
+		ret[strings.TrimSpace(key)] = value
+	}
+	return ret, nil
+}
+
+// expandOutputRefs replaces the references to the outputs of the steps in the
+// value. The outputs of a step are looked up by the name of the step.
+func expandOutputRefs(value string, lookup func(step string) map[string]string) (string, error) {
+	var lastErr error
+	ret := outputRefRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		m := outputRefRegex.FindStringSubmatch(ref)
+		step, name, path := m[1], m[2], m[3]
+		output, ok := lookup(step)[name]
+		if !ok {
+			lastErr = fmt.Errorf("%w: steps.%s.outputs.%s", errOutputNotFound, step, name)
+			return ref
+		}
+		if path == "" {
+			return output
+		}
+		v, err := evalJSONPath(output, path)
+		if err != nil {
+			lastErr = fmt.Errorf("steps.%s.outputs.%s%s: %w", step, name, path, err)
+			return ref
+		}
+		return v
+	})
+	return ret, lastErr
+}
+
+// expandMatrixRef expands the reference of a matrix variable with the output
+// variables and the outputs of the steps that have run. The environment of
+// the process is not used, so the reference always resolves to a value
+// produced in the same run.
+func expandMatrixRef(ref string, vars *dag.SyncMap, lookup func(step string) map[string]string) (string, error) {
+	ret := os.Expand(ref, func(name string) string {
+		if v, ok := vars.Variable(name); ok {
+			return v
+		}
+		if strings.HasPrefix(name, "steps.") {
+			// the reference to the outputs of a step is expanded below
+			return "${" + name + "}"
+		}
+		return ""
+	})
+	return expandOutputRefs(ret, lookup)
+}
+
+// evalJSONPath returns the value at the path such as ".items[0].name" in the
+// JSON value. The value is returned as it is if it is a string, and as JSON
+// otherwise.
+func evalJSONPath(value, path string) (string, error) {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return "", fmt.Errorf("%w: the output is not JSON", errInvalidJSONPath)
+	}
+	for _, m := range jsonPathRegex.FindAllStringSubmatch(path, -1) {
+		switch cur := v.(type) {
+		case map[string]any:
+			if m[1] == "" {
+				return "", fmt.Errorf("%w: %s is not an array", errInvalidJSONPath, m[0])
+			}
+			val, ok := cur[m[1]]
+			if !ok {
+				return "", fmt.Errorf("%w: %s not found", errInvalidJSONPath, m[1])
+			}
+			v = val
+		case []any:
+			if m[2] == "" {
+				return "", fmt.Errorf("%w: %s is not an object", errInvalidJSONPath, m[0])
+			}
+			i, _ := strconv.Atoi(m[2])
+			if i >= len(cur) {
+				return "", fmt.Errorf("%w: index %d out of range", errInvalidJSONPath, i)
+			}
+			v = cur[i]
+		default:
+			return "", fmt.Errorf("%w: %s not found", errInvalidJSONPath, m[0])
+		}
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/dagu-dev/dagu/internal/config"
 	"github.com/dagu-dev/dagu/internal/constants"
 	"github.com/dagu-dev/dagu/internal/dag"
+	"github.com/dagu-dev/dagu/internal/pool"
 )
 
 type Status int
@@ -21,11 +23,17 @@ const (
 	StatusError
 	StatusCancel
 	StatusSuccess
+	StatusQueued
+	StatusSkipped
 )
 
 var (
-	errUpstreamFailed  = fmt.Errorf("upstream failed")
-	errUpstreamSkipped = fmt.Errorf("upstream skipped")
+	errUpstreamFailed    = fmt.Errorf("upstream failed")
+	errUpstreamSkipped   = fmt.Errorf("upstream skipped")
+	errTriggerRuleNotMet = fmt.Errorf("trigger rule was not met")
+	errGenerateSteps     = fmt.Errorf("failed to generate steps")
+	errPoolNotFound      = fmt.Errorf("pool is not defined")
+	errPoolWaitCanceled  = fmt.Errorf("canceled while waiting for a slot of the pool")
 )
 
 func (s Status) String() string {
@@ -38,6 +46,10 @@ func (s Status) String() string {
 		return "canceled"
 	case StatusSuccess:
 		return "finished"
+	case StatusQueued:
+		return "queued"
+	case StatusSkipped:
+		return "skipped"
 	case StatusNone:
 		fallthrough
 	default:
@@ -50,6 +62,7 @@ type Scheduler struct {
 	*Config
 
 	canceled  int32
+	cancelCh  chan struct{} // cancelCh is closed when the scheduler is canceled.
 	mu        sync.RWMutex
 	pause     time.Duration
 	lastError error
@@ -66,6 +79,8 @@ type Config struct {
 	OnFailure     *dag.Step
 	OnCancel      *dag.Step
 	RequestId     string
+	Secrets       []string   // Secrets are the values to be masked in the logs of the steps.
+	Pools         pool.Pools // Pools are the resource pools that the steps acquire a slot of.
 }
 
 // Schedule runs the graph of steps.
@@ -85,7 +100,7 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 		}
 	NodesIteration:
 		for _, node := range g.Nodes() {
-			if node.State().Status != NodeStatusNone || !isReady(g, node) {
+			if st := node.State().Status; st != NodeStatusNone && st != NodeStatusWaiting || !isReady(g, node) {
 				continue NodesIteration
 			}
 			if sc.isCanceled() {
@@ -95,15 +110,23 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 				continue NodesIteration
 			}
 			// Check preconditions
-			if len(node.step.Preconditions) > 0 {
+			if node.step.WaitFor != nil {
+				if !sc.checkWaitFor(ctx, &wg, node, done) {
+					continue NodesIteration
+				}
+			} else if len(node.step.Preconditions) > 0 {
 				log.Printf("checking pre conditions for \"%s\"", node.step.Name)
-				if err := dag.EvalConditions(node.step.Preconditions); err != nil {
+				if err := dag.EvalStepConditions(&node.step, node.step.Preconditions); err != nil {
 					log.Printf("%s", err.Error())
 					node.setStatus(NodeStatusSkipped)
 					node.SetError(err)
 					continue NodesIteration
 				}
 			}
+			if len(node.step.Matrix) > 0 {
+				sc.expandMatrix(g, node, done)
+				continue NodesIteration
+			}
 			wg.Add(1)
 
 			log.Printf("start running: %s", node.step.Name)
@@ -115,7 +138,7 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 				}()
 
 				setupSucceed := true
-				if err := sc.setupNode(node); err != nil {
+				if err := sc.setupNode(g, node); err != nil {
 					setupSucceed = false
 					sc.lastError = err
 					node.setErr(err)
@@ -127,6 +150,11 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 			ExecRepeat:
 				for setupSucceed && !sc.isCanceled() {
 					execErr := sc.execNode(ctx, node)
+					if execErr != nil && node.repeatsOnExitCode() {
+						// the exit code tells the step needs to be repeated
+						execErr = nil
+						node.SetError(nil)
+					}
 					if execErr != nil {
 						status := node.State().Status
 						switch {
@@ -134,13 +162,25 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 							// do nothing
 						case sc.isCanceled():
 							sc.lastError = execErr
-						case node.step.RetryPolicy != nil && node.step.RetryPolicy.Limit > node.getRetryCount():
+						case node.shouldRetry():
 							// retry
 							log.Printf("%s failed but scheduled for retry", node.step.Name)
 							node.incRetryCount()
-							log.Printf("sleep %s for retry", node.step.RetryPolicy.Interval)
-							time.Sleep(node.step.RetryPolicy.Interval)
+							interval := node.retryInterval()
+							node.setNextRetryAt(time.Now().Add(interval))
+							if done != nil {
+								// notify the next retry time
+								done <- node
+							}
+							log.Printf("sleep %s for retry", interval)
+							if !sc.sleep(ctx, interval) {
+								// canceled while waiting for the retry
+								node.setNextRetryAt(time.Time{})
+								node.setStatus(NodeStatusCancel)
+								break
+							}
 							node.setRetriedAt(time.Now())
+							node.setNextRetryAt(time.Time{})
 							node.setStatus(NodeStatusNone)
 						default:
 							// finish the node
@@ -149,14 +189,28 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 							sc.lastError = execErr
 						}
 					}
+					if execErr == nil && node.step.GenerateSteps {
+						if err := sc.generateSteps(g, node); err != nil {
+							node.setErr(err)
+							sc.lastError = err
+							execErr = err
+						}
+					}
 					if node.State().Status != NodeStatusCancel {
 						node.incDoneCount()
 					}
 					if node.step.RepeatPolicy.Repeat {
 						if execErr == nil || node.step.ContinueOn.Failure {
 							if !sc.isCanceled() {
-								time.Sleep(node.step.RepeatPolicy.Interval)
-								continue ExecRepeat
+								finished, err := node.isRepeatFinished()
+								if err != nil {
+									node.setStatus(NodeStatusError)
+									node.setErr(err)
+									sc.lastError = err
+									execErr = err
+								} else if !finished && sc.sleep(ctx, node.step.RepeatPolicy.Interval) {
+									continue ExecRepeat
+								}
 							}
 						}
 					}
@@ -198,7 +252,7 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 		if n := sc.handlers[h]; n != nil {
 			log.Printf("%s started", n.step.Name)
 			n.step.OutputVariables = g.outputVariables
-			if err := sc.runHandlerNode(ctx, n); err != nil {
+			if err := sc.runHandlerNode(ctx, g, n); err != nil {
 				sc.lastError = err
 			}
 			if done != nil {
@@ -209,8 +263,126 @@ func (sc *Scheduler) Schedule(ctx context.Context, g *ExecutionGraph, done chan
 	return sc.lastError
 }
 
-func (sc *Scheduler) setupNode(node *Node) error {
+// expandMatrix expands the node with a matrix that refers to the output of
+// an upstream step. The expanded steps are added to the graph and the node
+// itself finishes without running the command.
+func (sc *Scheduler) expandMatrix(g *ExecutionGraph, node *Node, done chan *Node) {
+	log.Printf("expanding matrix: %s", node.step.Name)
+	node.setStartedAt(time.Now())
+	node.setStatus(NodeStatusRunning)
+
+	var err error
+	if !sc.Dry {
+		var vars []dag.MatrixVar
+		if vars, err = dag.ResolveMatrix(node.step.Matrix, func(ref string) (string, error) {
+			return expandMatrixRef(ref, node.step.OutputVariables, g.stepOutputs)
+		}); err == nil {
+			steps := dag.ExpandMatrix(node.step, vars)
+			for i := range steps {
+				steps[i].Depends = nil
+			}
+			err = g.addSteps(node, steps)
+		}
+	}
+	if err != nil {
+		log.Printf("failed to expand matrix: %s", err)
+		node.setErr(err)
+		sc.lastError = err
+	} else {
+		node.setStatus(NodeStatusSuccess)
+	}
+	node.finish()
+	if done != nil {
+		done <- node
+	}
+}
+
+// generateSteps adds the steps printed by the node to the graph.
+// The standard output of the node must be a YAML or JSON list of steps.
+func (sc *Scheduler) generateSteps(g *ExecutionGraph, node *Node) error {
+	if sc.Dry {
+		return nil
+	}
+	steps, err := dag.LoadSteps(node.generatedSteps(), node.step.Variables)
+	if err == nil {
+		err = g.addSteps(node, steps)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGenerateSteps, err)
+	}
+	log.Printf("%s generated %d steps", node.step.Name, len(steps))
+	return nil
+}
+
+// checkWaitFor starts waiting for the preconditions of the node in the
+// sensor mode. The preconditions are evaluated in a goroutine of the node,
+// so a slow precondition does not block the other nodes.
+// It returns true if the preconditions are met and the node is ready to run.
+func (sc *Scheduler) checkWaitFor(ctx context.Context, wg *sync.WaitGroup, node *Node, done chan *Node) bool {
+	if node.State().Status != NodeStatusNone {
+		return node.isConditionsMet()
+	}
+	log.Printf("waiting for pre conditions of \"%s\"", node.step.Name)
+	node.setStatus(NodeStatusWaiting)
+	node.setConditionsMet(false)
+	if done != nil {
+		done <- node
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sc.pollConditions(ctx, node, done)
+	}()
+	return false
+}
+
+// pollConditions evaluates the preconditions of the waiting node every poll
+// interval until they are met, the timeout is reached or the node is
+// canceled.
+func (sc *Scheduler) pollConditions(ctx context.Context, node *Node, done chan *Node) {
+	waitFor := node.step.WaitFor
+	waitingSince := time.Now()
+	for {
+		err := dag.EvalStepConditions(&node.step, node.step.Preconditions)
+		if node.State().Status != NodeStatusWaiting {
+			// the node was canceled while evaluating the preconditions
+			return
+		}
+		if err == nil {
+			node.SetError(nil)
+			node.setConditionsMet(true)
+			return
+		}
+		if waitFor.Timeout > 0 && time.Since(waitingSince) >= waitFor.Timeout {
+			err = fmt.Errorf("%w after %s: %v", errWaitForTimedOut, waitFor.Timeout, err)
+			log.Printf("%s", err.Error())
+			node.setStatus(NodeStatusError)
+			node.SetError(err)
+			sc.lastError = err
+			if done != nil {
+				done <- node
+			}
+			return
+		}
+		node.SetError(err)
+		select {
+		case <-ctx.Done():
+			node.cancel()
+			return
+		case <-time.After(waitFor.PollInterval):
+		}
+		if sc.isCanceled() {
+			return
+		}
+	}
+}
+
+func (sc *Scheduler) setupNode(g *ExecutionGraph, node *Node) error {
 	if !sc.Dry {
+		if err := node.expandOutputRefs(g.stepOutputs); err != nil {
+			return err
+		}
+		node.setSecrets(sc.Secrets)
 		return node.setup(sc.LogDir, sc.RequestId)
 	}
 	return nil
@@ -225,11 +397,51 @@ func (sc *Scheduler) teardownNode(node *Node) error {
 
 func (sc *Scheduler) execNode(ctx context.Context, n *Node) error {
 	if !sc.Dry {
+		if n.step.Pool != "" {
+			release, err := sc.acquireSlot(ctx, n)
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
 		return n.Execute(ctx)
 	}
 	return nil
 }
 
+// poolPollInterval is the interval to check for a free slot of the pool.
+var poolPollInterval = time.Millisecond * 500
+
+// acquireSlot waits for a free slot of the pool of the node and returns the
+// function to release it. The time spent waiting is added to the node state.
+func (sc *Scheduler) acquireSlot(ctx context.Context, n *Node) (func(), error) {
+	p, ok := sc.Pools.Get(n.step.Pool)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errPoolNotFound, n.step.Pool)
+	}
+	start := time.Now()
+	defer func() {
+		n.addPoolWaitTime(time.Since(start))
+	}()
+	for i := 0; ; i++ {
+		release, err := p.TryAcquire()
+		if err != nil || release != nil {
+			return release, err
+		}
+		if i == 0 {
+			log.Printf("%s is waiting for a slot of the pool %s", n.step.Name, p.Name)
+		}
+		if sc.isCanceled() {
+			return nil, errPoolWaitCanceled
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(poolPollInterval):
+		}
+	}
+}
+
 // Signal sends a signal to the scheduler.
 // for a node with repeat policy, it does not stop the node and
 // wait to finish current run.
@@ -302,8 +514,20 @@ func (sc *Scheduler) isCanceled() bool {
 }
 
 func isReady(g *ExecutionGraph, node *Node) bool {
+	switch node.step.TriggerRule {
+	case "", dag.TriggerRuleAllSuccess:
+		return isAllSuccess(g, node)
+	default:
+		return isTriggered(g, node)
+	}
+}
+
+// isAllSuccess returns true if all the dependencies of the node succeeded.
+// A dependency that failed or was skipped is treated as succeeded if its
+// ContinueOn allows it.
+func isAllSuccess(g *ExecutionGraph, node *Node) bool {
 	ready := true
-	for _, dep := range g.to[node.id] {
+	for _, dep := range g.dependencies(node.id) {
 		n := g.node(dep)
 		switch n.State().Status {
 		case NodeStatusSuccess:
@@ -323,6 +547,9 @@ func isReady(g *ExecutionGraph, node *Node) bool {
 		case NodeStatusCancel:
 			ready = false
 			node.setStatus(NodeStatusCancel)
+			if isUpstreamFailed(n) {
+				node.SetError(errUpstreamFailed)
+			}
 		case NodeStatusNone, NodeStatusRunning:
 			ready = false
 		default:
@@ -332,7 +559,67 @@ func isReady(g *ExecutionGraph, node *Node) bool {
 	return ready
 }
 
-func (sc *Scheduler) runHandlerNode(ctx context.Context, node *Node) error {
+// isUpstreamFailed returns true if the node was canceled because a step
+// upstream of it failed.
+func isUpstreamFailed(n *Node) bool {
+	state := n.State()
+	return state.Status == NodeStatusCancel && errors.Is(state.Error, errUpstreamFailed)
+}
+
+// isTriggered evaluates the trigger rule of the node against the status of
+// the dependencies. If the rule can no longer be met after all the
+// dependencies have finished, the node is skipped. A dependency canceled
+// because its upstream failed counts as failed, and any other canceled
+// dependency is finished but does not count as failed.
+func isTriggered(g *ExecutionGraph, node *Node) bool {
+	deps := g.dependencies(node.id)
+	if len(deps) == 0 {
+		return true
+	}
+	var finished, succeeded, failed, canceled int
+	for _, dep := range deps {
+		n := g.node(dep)
+		switch n.State().Status {
+		case NodeStatusSuccess:
+			finished++
+			succeeded++
+		case NodeStatusError:
+			finished++
+			failed++
+		case NodeStatusCancel:
+			finished++
+			if isUpstreamFailed(n) {
+				failed++
+			} else {
+				canceled++
+			}
+		case NodeStatusSkipped:
+			finished++
+		}
+	}
+	allFinished := finished == len(deps)
+
+	var ready bool
+	switch node.step.TriggerRule {
+	case dag.TriggerRuleAllDone:
+		ready = allFinished
+	case dag.TriggerRuleOneSuccess:
+		ready = succeeded > 0
+	case dag.TriggerRuleOneFailed:
+		ready = failed > 0
+	case dag.TriggerRuleAllFailed:
+		ready = allFinished && failed == len(deps)
+	case dag.TriggerRuleNoneFailed:
+		ready = allFinished && failed == 0 && canceled == 0
+	}
+	if !ready && allFinished {
+		node.setStatus(NodeStatusSkipped)
+		node.SetError(fmt.Errorf("%w: %s", errTriggerRuleNotMet, node.step.TriggerRule))
+	}
+	return ready
+}
+
+func (sc *Scheduler) runHandlerNode(ctx context.Context, g *ExecutionGraph, node *Node) error {
 	defer func() {
 		node.FinishedAt = time.Now()
 	}()
@@ -340,6 +627,11 @@ func (sc *Scheduler) runHandlerNode(ctx context.Context, node *Node) error {
 	node.setStatus(NodeStatusRunning)
 
 	if !sc.Dry {
+		if err := node.expandOutputRefs(g.stepOutputs); err != nil {
+			node.setErr(err)
+			return nil
+		}
+		node.setSecrets(sc.Secrets)
 		err := node.setup(sc.LogDir, sc.RequestId)
 		if err != nil {
 			node.setStatus(NodeStatusError)
@@ -390,7 +682,41 @@ func (sc *Scheduler) setup() (err error) {
 func (sc *Scheduler) setCanceled() {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
+	if sc.canceled == 1 {
+		return
+	}
 	sc.canceled = 1
+	if sc.cancelCh != nil {
+		close(sc.cancelCh)
+	}
+}
+
+// canceledCh returns the channel that is closed when the scheduler is canceled.
+func (sc *Scheduler) canceledCh() <-chan struct{} {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.cancelCh == nil {
+		sc.cancelCh = make(chan struct{})
+		if sc.canceled == 1 {
+			close(sc.cancelCh)
+		}
+	}
+	return sc.cancelCh
+}
+
+// sleep waits for the duration. It returns false if the context is done or
+// the scheduler is canceled before the duration has passed.
+func (sc *Scheduler) sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-sc.canceledCh():
+		return false
+	}
 }
 
 func (sc *Scheduler) runningCount(g *ExecutionGraph) int {
@@ -405,7 +731,7 @@ func (sc *Scheduler) runningCount(g *ExecutionGraph) int {
 
 func (sc *Scheduler) isFinished(g *ExecutionGraph) bool {
 	for _, node := range g.Nodes() {
-		if node.State().Status == NodeStatusRunning || node.State().Status == NodeStatusNone {
+		if st := node.State().Status; st == NodeStatusRunning || st == NodeStatusNone || st == NodeStatusWaiting {
 			return false
 		}
 	}
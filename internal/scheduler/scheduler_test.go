@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path"
 	"sync/atomic"
@@ -12,6 +13,7 @@ import (
 	"github.com/dagu-dev/dagu/internal/config"
 	"github.com/dagu-dev/dagu/internal/constants"
 	"github.com/dagu-dev/dagu/internal/dag"
+	"github.com/dagu-dev/dagu/internal/pool"
 	"github.com/dagu-dev/dagu/internal/util"
 	"github.com/stretchr/testify/require"
 )
@@ -145,6 +147,386 @@ func TestSchedulerAllowSkipped(t *testing.T) {
 	require.Equal(t, NodeStatusSuccess, nodes[2].State().Status)
 }
 
+func TestSchedulerTriggerRule(t *testing.T) {
+	for _, tc := range []struct {
+		rule     dag.TriggerRule
+		commands []string
+		expected NodeStatus
+	}{
+		{dag.TriggerRuleAllDone, []string{testCommand, testCommandFail}, NodeStatusSuccess},
+		{dag.TriggerRuleOneSuccess, []string{testCommand, testCommandFail}, NodeStatusSuccess},
+		{dag.TriggerRuleOneSuccess, []string{testCommandFail, testCommandFail}, NodeStatusSkipped},
+		{dag.TriggerRuleOneFailed, []string{testCommand, testCommandFail}, NodeStatusSuccess},
+		{dag.TriggerRuleOneFailed, []string{testCommand, testCommand}, NodeStatusSkipped},
+		{dag.TriggerRuleAllFailed, []string{testCommandFail, testCommandFail}, NodeStatusSuccess},
+		{dag.TriggerRuleAllFailed, []string{testCommand, testCommandFail}, NodeStatusSkipped},
+		{dag.TriggerRuleNoneFailed, []string{testCommand, testCommand}, NodeStatusSuccess},
+		{dag.TriggerRuleNoneFailed, []string{testCommand, testCommandFail}, NodeStatusSkipped},
+	} {
+		t.Run(fmt.Sprintf("%s%v", tc.rule, tc.commands), func(t *testing.T) {
+			ruled := step("3", testCommand, "1", "2")
+			ruled.TriggerRule = tc.rule
+			g, _, _ := testSchedule(t,
+				step("1", tc.commands[0]),
+				step("2", tc.commands[1]),
+				ruled,
+			)
+
+			node := g.Nodes()[2]
+			require.Equal(t, tc.expected, node.State().Status)
+			if tc.expected == NodeStatusSkipped {
+				require.ErrorIs(t, node.State().Error, errTriggerRuleNotMet)
+			}
+		})
+	}
+	t.Run("UpstreamCanceled", func(t *testing.T) {
+		cleanup := step("3", testCommand, "2")
+		cleanup.TriggerRule = dag.TriggerRuleAllDone
+		g, _, err := testSchedule(t,
+			step("1", testCommandFail),
+			step("2", testCommand, "1"),
+			cleanup,
+		)
+		require.Error(t, err)
+
+		nodes := g.Nodes()
+		require.Equal(t, NodeStatusCancel, nodes[1].State().Status)
+		require.Equal(t, NodeStatusSuccess, nodes[2].State().Status)
+	})
+	t.Run("UpstreamFailedChain", func(t *testing.T) {
+		for rule, expected := range map[dag.TriggerRule]NodeStatus{
+			dag.TriggerRuleOneFailed:  NodeStatusSuccess,
+			dag.TriggerRuleAllFailed:  NodeStatusSuccess,
+			dag.TriggerRuleNoneFailed: NodeStatusSkipped,
+			dag.TriggerRuleOneSuccess: NodeStatusSkipped,
+			dag.TriggerRuleAllDone:    NodeStatusSuccess,
+			dag.TriggerRuleAllSuccess: NodeStatusCancel,
+		} {
+			fallback := step("4", testCommand, "3")
+			fallback.TriggerRule = rule
+			g, _, err := testSchedule(t,
+				step("1", testCommandFail),
+				step("2", testCommand, "1"),
+				step("3", testCommand, "2"),
+				fallback,
+			)
+			require.Error(t, err)
+
+			nodes := g.Nodes()
+			require.Equal(t, NodeStatusCancel, nodes[1].State().Status)
+			require.Equal(t, NodeStatusCancel, nodes[2].State().Status)
+			require.Equal(t, expected, nodes[3].State().Status, rule)
+		}
+	})
+}
+
+func TestSchedulerWaitFor(t *testing.T) {
+	waitStep := func(file string, timeout time.Duration) dag.Step {
+		s := step("2", testCommand, "1")
+		s.Preconditions = []*dag.Condition{
+			{Condition: fmt.Sprintf("`cat %s 2>/dev/null || true`", file), Expected: "ready"},
+		}
+		s.WaitFor = &dag.WaitFor{PollInterval: time.Millisecond * 100, Timeout: timeout}
+		return s
+	}
+
+	t.Run("PreconditionsMet", func(t *testing.T) {
+		file := path.Join(t.TempDir(), "flag")
+		g, sc := newTestSchedule(t, &Config{},
+			step("1", testCommand),
+			waitStep(file, 0),
+		)
+		go func() {
+			time.Sleep(time.Millisecond * 500)
+			_ = os.WriteFile(file, []byte("ready"), 0600)
+		}()
+		done := make(chan *Node)
+		var waited atomic.Bool
+		go func() {
+			for n := range done {
+				if n.State().Status == NodeStatusWaiting {
+					waited.Store(true)
+				}
+			}
+		}()
+		err := sc.Schedule(context.Background(), g, done)
+		close(done)
+		require.NoError(t, err)
+
+		nodes := g.Nodes()
+		require.True(t, waited.Load())
+		require.Equal(t, NodeStatusSuccess, nodes[1].State().Status)
+		require.NoError(t, nodes[1].State().Error)
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		file := path.Join(t.TempDir(), "flag")
+		g, sc := newTestSchedule(t, &Config{},
+			step("1", testCommand),
+			waitStep(file, time.Millisecond*300),
+			step("3", testCommand, "2"),
+		)
+		err := sc.Schedule(context.Background(), g, nil)
+		require.ErrorIs(t, err, errWaitForTimedOut)
+
+		nodes := g.Nodes()
+		require.Equal(t, StatusError, sc.Status(g))
+		require.Equal(t, NodeStatusError, nodes[1].State().Status)
+		require.Equal(t, NodeStatusCancel, nodes[2].State().Status)
+	})
+	t.Run("SlowPreconditions", func(t *testing.T) {
+		slow := step("1", testCommand)
+		slow.Preconditions = []*dag.Condition{
+			{Condition: "`sleep 1`", Expected: ""},
+		}
+		slow.WaitFor = &dag.WaitFor{PollInterval: time.Millisecond * 100}
+		g, sc := newTestSchedule(t, &Config{},
+			slow,
+			step("2", testCommand),
+			step("3", testCommand, "2"),
+		)
+		err := sc.Schedule(context.Background(), g, nil)
+		require.NoError(t, err)
+
+		// the other steps are not blocked while the preconditions are evaluated
+		nodes := g.Nodes()
+		require.Equal(t, NodeStatusSuccess, nodes[0].State().Status)
+		require.True(t, nodes[2].State().FinishedAt.Before(nodes[0].State().StartedAt))
+	})
+	t.Run("Cancel", func(t *testing.T) {
+		file := path.Join(t.TempDir(), "flag")
+		g, sc := newTestSchedule(t, &Config{},
+			step("1", testCommand),
+			waitStep(file, 0),
+		)
+		go func() {
+			time.Sleep(time.Millisecond * 500)
+			sc.Cancel(g)
+		}()
+		_ = sc.Schedule(context.Background(), g, nil)
+
+		nodes := g.Nodes()
+		require.Equal(t, StatusCancel, sc.Status(g))
+		require.Equal(t, NodeStatusCancel, nodes[1].State().Status)
+	})
+}
+
+func TestSchedulerPool(t *testing.T) {
+	poolStep := func(name string) dag.Step {
+		s := step(name, "sleep 0.5")
+		s.Pool = "db"
+		return s
+	}
+
+	t.Run("WaitForSlot", func(t *testing.T) {
+		pools := pool.New(t.TempDir(), map[string]int{"db": 1})
+		g, sc := newTestSchedule(t, &Config{MaxActiveRuns: 2, Pools: pools},
+			poolStep("1"),
+			poolStep("2"),
+		)
+		err := sc.Schedule(context.Background(), g, nil)
+		require.NoError(t, err)
+
+		// one of the steps waits until the other releases the slot
+		nodes := g.Nodes()
+		waited := max(nodes[0].State().PoolWaitTime, nodes[1].State().PoolWaitTime)
+		require.Greater(t, waited, time.Millisecond*300)
+		require.Equal(t, NodeStatusSuccess, nodes[0].State().Status)
+		require.Equal(t, NodeStatusSuccess, nodes[1].State().Status)
+	})
+	t.Run("PoolNotFound", func(t *testing.T) {
+		g, sc := newTestSchedule(t, &Config{Pools: pool.Pools{}},
+			poolStep("1"),
+		)
+		err := sc.Schedule(context.Background(), g, nil)
+		require.ErrorIs(t, err, errPoolNotFound)
+		require.Equal(t, NodeStatusError, g.Nodes()[0].State().Status)
+	})
+	t.Run("Cancel", func(t *testing.T) {
+		pools := pool.New(t.TempDir(), map[string]int{"db": 1})
+		p, _ := pools.Get("db")
+		release, err := p.TryAcquire()
+		require.NoError(t, err)
+		defer release()
+
+		g, sc := newTestSchedule(t, &Config{Pools: pools},
+			poolStep("1"),
+		)
+		go func() {
+			time.Sleep(time.Millisecond * 500)
+			sc.Cancel(g)
+		}()
+		_ = sc.Schedule(context.Background(), g, nil)
+
+		require.Equal(t, StatusCancel, sc.Status(g))
+		require.Equal(t, NodeStatusCancel, g.Nodes()[0].State().Status)
+	})
+}
+
+func TestSchedulerMatrix(t *testing.T) {
+	t.Run("UpstreamOutput", func(t *testing.T) {
+		list := step("1", `echo '["a","b"]'`)
+		list.Output = "TEST_MATRIX_ITEMS"
+		process := step("2", "echo $ITEM", "1")
+		process.Matrix = []dag.MatrixVar{{Name: "ITEM", Ref: "$TEST_MATRIX_ITEMS"}}
+		g, sc, err := testSchedule(t,
+			list,
+			process,
+			step("3", testCommand, "2"),
+		)
+		require.NoError(t, err)
+		require.Equal(t, StatusSuccess, sc.Status(g))
+
+		nodes := g.Nodes()
+		require.Len(t, nodes, 5)
+		require.Equal(t, "2[ITEM=a]", nodes[3].Step().Name)
+		require.Equal(t, []string{"a"}, nodes[3].Step().Args)
+		require.Equal(t, "2[ITEM=b]", nodes[4].Step().Name)
+		for _, n := range nodes {
+			require.Equal(t, NodeStatusSuccess, n.State().Status, n.Step().Name)
+		}
+		require.Equal(t, []string{"2", "2[ITEM=a]", "2[ITEM=b]"}, nodes[2].Step().Depends)
+	})
+	t.Run("StepOutputs", func(t *testing.T) {
+		list := step("1", "sh")
+		list.Script = `echo '{"items": ["a", "b"]}' > $DAGU_OUTPUT`
+		process := step("2", "echo $ITEM", "1")
+		process.Matrix = []dag.MatrixVar{{Name: "ITEM", Ref: "${steps.1.outputs.items}"}}
+		g, sc, err := testSchedule(t, list, process)
+		require.NoError(t, err)
+		require.Equal(t, StatusSuccess, sc.Status(g))
+
+		nodes := g.Nodes()
+		require.Len(t, nodes, 4)
+		require.Equal(t, "2[ITEM=a]", nodes[2].Step().Name)
+		require.Equal(t, "2[ITEM=b]", nodes[3].Step().Name)
+	})
+	t.Run("EnvironmentNotUsed", func(t *testing.T) {
+		t.Setenv("TEST_MATRIX_ENV", `["a","b"]`)
+		process := step("1", "echo $ITEM")
+		process.Matrix = []dag.MatrixVar{{Name: "ITEM", Ref: "$TEST_MATRIX_ENV"}}
+		g, sc, err := testSchedule(t, process)
+		require.ErrorContains(t, err, "matrix reference must be a JSON array")
+		require.Equal(t, StatusError, sc.Status(g))
+	})
+	t.Run("NotJSONArray", func(t *testing.T) {
+		list := step("1", "echo a,b")
+		list.Output = "TEST_MATRIX_INVALID"
+		process := step("2", "echo $ITEM", "1")
+		process.Matrix = []dag.MatrixVar{{Name: "ITEM", Ref: "$TEST_MATRIX_INVALID"}}
+		g, sc, err := testSchedule(t,
+			list,
+			process,
+			step("3", testCommand, "2"),
+		)
+		require.Error(t, err)
+		require.Equal(t, StatusError, sc.Status(g))
+
+		nodes := g.Nodes()
+		require.Equal(t, NodeStatusError, nodes[1].State().Status)
+		require.Equal(t, NodeStatusCancel, nodes[2].State().Status)
+	})
+}
+
+func TestSchedulerGenerateSteps(t *testing.T) {
+	t.Run("GenerateSteps", func(t *testing.T) {
+		gen := step("1", `echo '[{"name": "a", "command": "echo a"}, {"name": "b", "command": "echo b", "depends": ["a"]}]'`)
+		gen.GenerateSteps = true
+		g, sc, err := testSchedule(t,
+			gen,
+			step("2", testCommand, "1"),
+		)
+		require.NoError(t, err)
+		require.Equal(t, StatusSuccess, sc.Status(g))
+
+		nodes := g.Nodes()
+		require.Len(t, nodes, 4)
+		require.Equal(t, "a", nodes[2].Step().Name)
+		require.Equal(t, "1", nodes[2].Step().GeneratedBy)
+		require.Equal(t, "b", nodes[3].Step().Name)
+		require.Equal(t, []string{"a"}, nodes[3].Step().Depends)
+		require.Equal(t, []string{"1", "a", "b"}, nodes[1].Step().Depends)
+		for _, n := range nodes {
+			require.Equal(t, NodeStatusSuccess, n.State().Status, n.Step().Name)
+		}
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		gen := step("1", `echo '[{"name": "a", "command": "echo a", "depends": ["2"]}]'`)
+		gen.GenerateSteps = true
+		g, sc, err := testSchedule(t,
+			gen,
+			step("2", testCommand, "1"),
+		)
+		require.ErrorIs(t, err, errGenerateSteps)
+		require.Equal(t, StatusError, sc.Status(g))
+
+		nodes := g.Nodes()
+		require.Len(t, nodes, 2)
+		require.Equal(t, NodeStatusError, nodes[0].State().Status)
+		require.Equal(t, NodeStatusCancel, nodes[1].State().Status)
+	})
+	t.Run("InvalidOutput", func(t *testing.T) {
+		gen := step("1", `echo '[{"name": "a", "unknown": "x"}]'`)
+		gen.GenerateSteps = true
+		g, _, err := testSchedule(t, gen)
+		require.ErrorIs(t, err, errGenerateSteps)
+		require.Len(t, g.Nodes(), 1)
+	})
+}
+
+func TestSchedulerStepOutputs(t *testing.T) {
+	t.Run("KeyValue", func(t *testing.T) {
+		extract := step("extract", "sh")
+		extract.Script = `echo "count=3" >> $DAGU_OUTPUT
+echo "name=a b" >> $DAGU_OUTPUT`
+		use := step("use", "echo ${steps.extract.outputs.count} ${steps.extract.outputs.name}", "extract")
+		g, sc, err := testSchedule(t, extract, use)
+		require.NoError(t, err)
+		require.Equal(t, StatusSuccess, sc.Status(g))
+
+		nodes := g.Nodes()
+		require.Equal(t, map[string]string{"count": "3", "name": "a b"}, nodes[0].State().Outputs)
+		require.Equal(t, []string{"3", "a b"}, nodes[1].Step().Args)
+	})
+	t.Run("JSONPath", func(t *testing.T) {
+		extract := step("extract", "sh")
+		extract.Script = `echo '{"result": {"items": [{"name": "a"}, {"name": "b"}]}}' > $DAGU_OUTPUT`
+		use := step("use", "echo ${steps.extract.outputs.result.items[1].name}", "extract")
+		g, sc, err := testSchedule(t, extract, use)
+		require.NoError(t, err)
+		require.Equal(t, StatusSuccess, sc.Status(g))
+		require.Equal(t, []string{"b"}, g.Nodes()[1].Step().Args)
+	})
+	t.Run("Dir", func(t *testing.T) {
+		dir := t.TempDir()
+		extract := step("extract", "sh")
+		extract.Script = fmt.Sprintf(`echo "dir=%s" > $DAGU_OUTPUT`, dir)
+		use := step("use", "sh", "extract")
+		use.Script = `echo "pwd=$(pwd)" > $DAGU_OUTPUT`
+		use.Dir = "${steps.extract.outputs.dir}"
+		g, sc, err := testSchedule(t, extract, use)
+		require.NoError(t, err)
+		require.Equal(t, StatusSuccess, sc.Status(g))
+		require.Equal(t, dir, g.Nodes()[1].Step().Dir)
+		require.Equal(t, map[string]string{"pwd": dir}, g.Nodes()[1].State().Outputs)
+	})
+	t.Run("InvalidOutputs", func(t *testing.T) {
+		extract := step("extract", "sh")
+		extract.Script = `echo "invalid" > $DAGU_OUTPUT`
+		g, sc, err := testSchedule(t, extract)
+		require.ErrorIs(t, err, errInvalidOutputs)
+		require.Equal(t, StatusError, sc.Status(g))
+	})
+	t.Run("OutputNotFound", func(t *testing.T) {
+		g, sc, err := testSchedule(t,
+			step("extract", testCommand),
+			step("use", "echo ${steps.extract.outputs.count}", "extract"),
+		)
+		require.ErrorIs(t, err, errOutputNotFound)
+		require.Equal(t, StatusError, sc.Status(g))
+		require.Equal(t, NodeStatusError, g.Nodes()[1].State().Status)
+	})
+}
+
 func TestSchedulerCancel(t *testing.T) {
 
 	g, _ := NewExecutionGraph(
@@ -171,6 +553,31 @@ func TestSchedulerCancel(t *testing.T) {
 	require.Equal(t, NodeStatusNone, nodes[2].State().Status)
 }
 
+func TestSchedulerCancelRetry(t *testing.T) {
+	g, _ := NewExecutionGraph(
+		dag.Step{
+			Name:        "1",
+			Command:     testCommandFail,
+			RetryPolicy: &dag.RetryPolicy{Limit: 1, Interval: time.Second * 10},
+		},
+	)
+	sc := &Scheduler{Config: &Config{}}
+
+	go func() {
+		time.Sleep(time.Millisecond * 300)
+		sc.Cancel(g)
+	}()
+
+	start := time.Now()
+	_ = sc.Schedule(context.Background(), g, nil)
+	require.Less(t, time.Since(start), time.Second*5)
+
+	nodes := g.Nodes()
+	require.Equal(t, StatusCancel, sc.Status(g))
+	require.Equal(t, NodeStatusCancel, nodes[0].State().Status)
+	require.Equal(t, 1, nodes[0].State().RetryCount)
+}
+
 func TestSchedulerRetryFail(t *testing.T) {
 	cmd := path.Join(util.MustGetwd(), "testdata/testfile.sh")
 	g, sc, err := testSchedule(t,
@@ -208,6 +615,36 @@ func TestSchedulerRetryFail(t *testing.T) {
 	require.Equal(t, nodes[1].State().RetryCount, 1)
 }
 
+func TestSchedulerRetryExitCodes(t *testing.T) {
+	g, sc, err := testSchedule(t,
+		dag.Step{
+			Name:    "1",
+			Command: "sh",
+			Args:    []string{"-c", "exit 1"},
+			RetryPolicy: &dag.RetryPolicy{
+				Limit:     2,
+				ExitCodes: []int{75},
+			},
+		},
+		dag.Step{
+			Name:    "2",
+			Command: "sh",
+			Args:    []string{"-c", "exit 75"},
+			RetryPolicy: &dag.RetryPolicy{
+				Limit:     2,
+				ExitCodes: []int{75},
+			},
+		},
+	)
+	require.Error(t, err)
+	require.Equal(t, sc.Status(g), StatusError)
+
+	nodes := g.Nodes()
+	require.Equal(t, 0, nodes[0].State().RetryCount)
+	require.Equal(t, 2, nodes[1].State().RetryCount)
+	require.True(t, nodes[1].State().NextRetryAt.IsZero())
+}
+
 func TestSchedulerRetrySuccess(t *testing.T) {
 	cmd := path.Join(util.MustGetwd(), "testdata/testfile.sh")
 	tmpDir, err := os.MkdirTemp("", "scheduler_test")
@@ -318,6 +755,25 @@ func TestStepPreCondition(t *testing.T) {
 	require.Equal(t, NodeStatusSuccess, nodes[4].State().Status)
 }
 
+func TestSchedulerStepTimeout(t *testing.T) {
+	g, sc, err := testSchedule(t,
+		dag.Step{
+			Name:    "1",
+			Command: "sleep",
+			Args:    []string{"10"},
+			Timeout: time.Millisecond * 100,
+		},
+		step("2", testCommand, "1"),
+	)
+	require.ErrorIs(t, err, errStepTimedOut)
+	require.Equal(t, sc.Status(g), StatusError)
+
+	nodes := g.Nodes()
+	require.Equal(t, NodeStatusError, nodes[0].State().Status)
+	require.True(t, nodes[0].State().TimedOut)
+	require.Equal(t, NodeStatusCancel, nodes[1].State().Status)
+}
+
 func TestSchedulerOnExit(t *testing.T) {
 	onExitStep := step("onExit", testCommand)
 	g, sc := newTestSchedule(t,
@@ -502,6 +958,34 @@ func TestRepeat(t *testing.T) {
 	require.Equal(t, nodes[0].DoneCount, 2)
 }
 
+func TestRepeatCancelInterval(t *testing.T) {
+	g, _ := NewExecutionGraph(
+		dag.Step{
+			Name:    "1",
+			Command: testCommand,
+			RepeatPolicy: dag.RepeatPolicy{
+				Repeat:   true,
+				Interval: time.Second * 10,
+			},
+		},
+	)
+	sc := &Scheduler{Config: &Config{}}
+
+	go func() {
+		time.Sleep(time.Millisecond * 300)
+		sc.Cancel(g)
+	}()
+
+	start := time.Now()
+	err := sc.Schedule(context.Background(), g, nil)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), time.Second*5)
+
+	nodes := g.Nodes()
+	require.Equal(t, StatusCancel, sc.Status(g))
+	require.Equal(t, 1, nodes[0].DoneCount)
+}
+
 func TestRepeatFail(t *testing.T) {
 	g, _ := NewExecutionGraph(
 		dag.Step{
@@ -523,6 +1007,100 @@ func TestRepeatFail(t *testing.T) {
 	require.Equal(t, nodes[0].DoneCount, 1)
 }
 
+func TestRepeatUntilCondition(t *testing.T) {
+	file := path.Join(t.TempDir(), "count")
+	g, sc := newTestSchedule(t, &Config{},
+		dag.Step{
+			Name:    "1",
+			Command: "sh",
+			Args:    []string{"-c", fmt.Sprintf("printf x >> %s", file)},
+			RepeatPolicy: dag.RepeatPolicy{
+				Repeat:    true,
+				Interval:  time.Millisecond * 100,
+				Condition: &dag.Condition{Condition: fmt.Sprintf("`cat %s`", file), Expected: "xxx"},
+			},
+		},
+		step("2", testCommand, "1"),
+	)
+	err := sc.Schedule(context.Background(), g, nil)
+	require.NoError(t, err)
+
+	nodes := g.Nodes()
+	require.Equal(t, StatusSuccess, sc.Status(g))
+	require.Equal(t, NodeStatusSuccess, nodes[0].State().Status)
+	require.Equal(t, 3, nodes[0].State().DoneCount)
+	require.Equal(t, NodeStatusSuccess, nodes[1].State().Status)
+}
+
+func TestRepeatWhileExitCode(t *testing.T) {
+	file := path.Join(t.TempDir(), "count")
+	g, sc := newTestSchedule(t, &Config{},
+		dag.Step{
+			Name:    "1",
+			Command: "sh",
+			Args: []string{"-c", fmt.Sprintf(
+				"printf x >> %s; [ $(cat %s) = xx ] || exit 3", file, file)},
+			RepeatPolicy: dag.RepeatPolicy{
+				Repeat:   true,
+				Interval: time.Millisecond * 100,
+				ExitCode: []int{3},
+			},
+		},
+	)
+	err := sc.Schedule(context.Background(), g, nil)
+	require.NoError(t, err)
+
+	nodes := g.Nodes()
+	require.Equal(t, StatusSuccess, sc.Status(g))
+	require.Equal(t, NodeStatusSuccess, nodes[0].State().Status)
+	require.Equal(t, 2, nodes[0].State().DoneCount)
+}
+
+func TestRepeatLimit(t *testing.T) {
+	t.Run("Unconditional", func(t *testing.T) {
+		g, sc := newTestSchedule(t, &Config{},
+			dag.Step{
+				Name:    "1",
+				Command: testCommand,
+				RepeatPolicy: dag.RepeatPolicy{
+					Repeat:   true,
+					Interval: time.Millisecond * 100,
+					Limit:    2,
+				},
+			},
+		)
+		err := sc.Schedule(context.Background(), g, nil)
+		require.NoError(t, err)
+
+		nodes := g.Nodes()
+		require.Equal(t, NodeStatusSuccess, nodes[0].State().Status)
+		require.Equal(t, 2, nodes[0].State().DoneCount)
+	})
+	t.Run("ConditionNotMet", func(t *testing.T) {
+		g, sc := newTestSchedule(t, &Config{},
+			dag.Step{
+				Name:    "1",
+				Command: testCommand,
+				RepeatPolicy: dag.RepeatPolicy{
+					Repeat:    true,
+					Interval:  time.Millisecond * 100,
+					Condition: &dag.Condition{Condition: "`echo no`", Expected: "yes"},
+					Limit:     2,
+				},
+			},
+			step("2", testCommand, "1"),
+		)
+		err := sc.Schedule(context.Background(), g, nil)
+		require.ErrorIs(t, err, errRepeatLimitReached)
+
+		nodes := g.Nodes()
+		require.Equal(t, StatusError, sc.Status(g))
+		require.Equal(t, NodeStatusError, nodes[0].State().Status)
+		require.Equal(t, 2, nodes[0].State().DoneCount)
+		require.Equal(t, NodeStatusCancel, nodes[1].State().Status)
+	})
+}
+
 func TestStopRepetitiveTaskGracefully(t *testing.T) {
 	g, _ := NewExecutionGraph(
 		dag.Step{
@@ -563,6 +1141,8 @@ func TestSchedulerStatusText(t *testing.T) {
 		StatusError:   "failed",
 		StatusCancel:  "canceled",
 		StatusSuccess: "finished",
+		StatusQueued:  "queued",
+		StatusSkipped: "skipped",
 	} {
 		require.Equal(t, k.String(), v)
 	}
@@ -574,6 +1154,7 @@ func TestSchedulerStatusText(t *testing.T) {
 		NodeStatusCancel:  "canceled",
 		NodeStatusSuccess: "finished",
 		NodeStatusSkipped: "skipped",
+		NodeStatusWaiting: "waiting",
 	} {
 		require.Equal(t, k.String(), v)
 	}
@@ -641,7 +1222,8 @@ func TestTakeOutputFromPrevStep(t *testing.T) {
 	require.Equal(t, NodeStatusSuccess, nodes[0].State().Status)
 	require.Equal(t, NodeStatusSuccess, nodes[1].State().Status)
 
-	require.Equal(t, "take-output", os.ExpandEnv("$TOOK_PREV_OUT"))
+	require.Equal(t, "take-output", g.OutputVariables()["TOOK_PREV_OUT"])
+	require.Empty(t, os.Getenv("TOOK_PREV_OUT"))
 }
 
 func step(name, command string, depends ...string) dag.Step {
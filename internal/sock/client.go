@@ -55,6 +55,16 @@ func (cl *Client) Request(method, url string) (string, error) {
 	return string(body), nil
 }
 
+// IsListening returns true if a server is listening on the unix socket.
+func IsListening(addr string) bool {
+	conn, err := net.DialTimeout("unix", addr, timeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
 func procError(action string, err error) error {
 	if err, ok := err.(net.Error); ok && err.Timeout() {
 		return fmt.Errorf("%s timeout %w: %s", action, ErrTimeout, err.Error())
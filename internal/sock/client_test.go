@@ -22,6 +22,7 @@ func TestDialFail(t *testing.T) {
 	client := Client{Addr: f.Name()}
 	_, err = client.Request("GET", "/status")
 	require.Error(t, err)
+	require.False(t, IsListening(f.Name()))
 }
 
 func TestDialTimeout(t *testing.T) {
@@ -49,6 +50,7 @@ func TestDialTimeout(t *testing.T) {
 	time.Sleep(time.Millisecond * 500)
 
 	require.NoError(t, err)
+	require.True(t, IsListening(f.Name()))
 	client := Client{Addr: f.Name()}
 	_, err = client.Request("GET", "/status")
 	require.Error(t, err)
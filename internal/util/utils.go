@@ -65,6 +65,21 @@ var (
 // SplitCommand splits command string to program and arguments.
 // TODO: This function needs to be refactored to handle more complex cases.
 func SplitCommand(cmd string, parse bool) (cmdx string, args []string) {
+	var expand func(string) string
+	if parse {
+		expand = os.ExpandEnv
+	}
+	return splitCommand(cmd, parse, expand)
+}
+
+// SplitCommandWithEnv splits command string to program and arguments like
+// SplitCommand with parse enabled, but expands the variables in the
+// arguments with the given function.
+func SplitCommandWithEnv(cmd string, expand func(string) string) (cmdx string, args []string) {
+	return splitCommand(cmd, true, expand)
+}
+
+func splitCommand(cmd string, parse bool, expand func(string) string) (cmdx string, args []string) {
 	splits := strings.SplitN(cmd, " ", 2)
 	if len(splits) == 1 {
 		return splits[0], []string{}
@@ -86,8 +101,8 @@ func SplitCommand(cmd string, parse bool) (cmdx string, args []string) {
 	var ret []string
 	for _, v := range args {
 		val := unescapeReplacer.Replace(v)
-		if parse {
-			val = os.ExpandEnv(val)
+		if expand != nil {
+			val = expand(val)
 		}
 		ret = append(ret, val)
 	}
@@ -0,0 +1,9 @@
+// Package schemas provides the JSON schemas of the files that Dagu reads.
+package schemas
+
+import _ "embed"
+
+// DAG is the JSON schema of the DAG files.
+//
+//go:embed dag.schema.json
+var DAG []byte
@@ -5,6 +5,7 @@ import (
 	"embed"
 
 	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/engine"
 	"github.com/dagu-dev/dagu/internal/logger"
 	"github.com/dagu-dev/dagu/service/frontend/handlers"
 	"github.com/dagu-dev/dagu/service/frontend/server"
@@ -30,14 +31,16 @@ type Params struct {
 	Handlers []server.New `group:"handlers"`
 }
 
-func LifetimeHooks(lc fx.Lifecycle, srv *server.Server) {
+func LifetimeHooks(lc fx.Lifecycle, srv *server.Server, dp *engine.Dispatcher) {
 	lc.Append(
 		fx.Hook{
 			OnStart: func(ctx context.Context) (err error) {
+				dp.Start()
 				return srv.Serve(ctx)
 			},
 			OnStop: func(_ context.Context) error {
 				srv.Shutdown()
+				dp.Stop()
 				return nil
 			},
 		},
@@ -183,12 +183,21 @@ func (h *DAGHandler) GetDetail(params operations.GetDagDetailsParams) (*models.G
 
 	switch tab {
 	case dagTabTypeStatus:
+		running, err := e.GetRunningStatuses(dagStatus.DAG)
+		if err != nil {
+			resp.Errors = append(resp.Errors, err.Error())
+		}
+		resp.RunningStatuses = lo.Map(running, func(s *domain.Status, _ int) *models.DagStatusDetail {
+			return response.ToDagStatusDetail(s)
+		})
+
 	case dagTabTypeSpec:
 		dagContent, err := e.GetDAGSpec(dagID)
 		if err != nil {
 			return nil, response.NewNotFoundError(err)
 		}
 		resp.Definition = lo.ToPtr(dagContent)
+		resp.Revision = persistence.SpecRevision([]byte(dagContent))
 
 	case dagTabTypeHistory:
 		e := h.engineFactory.Create()
@@ -331,21 +340,32 @@ func (h *DAGHandler) PostAction(params operations.PostDagActionParams) (*models.
 
 	switch *params.Body.Action {
 	case "start":
-		if d.Status.Status == scheduler.StatusRunning {
-			return nil, response.NewBadRequestError(errInvalidArgs)
+		running, err := e.GetRunningStatuses(d.DAG)
+		if err != nil {
+			return nil, response.NewInternalError(err)
+		}
+		queued, err := e.GetQueuedStatuses(d.DAG)
+		if err != nil {
+			return nil, response.NewInternalError(err)
+		}
+		if len(running)+len(queued) >= d.DAG.RunLimit() {
+			return nil, response.NewBadRequestError(fmt.Errorf("the DAG is already running: %w", errInvalidArgs))
 		}
 		e := h.engineFactory.Create()
-		e.StartAsync(d.DAG, params.Body.Params)
+		if err := e.StartAsync(d.DAG, params.Body.Params); err != nil {
+			return nil, response.NewInternalError(fmt.Errorf("error trying to start the DAG: %w", err))
+		}
 
 	case "suspend":
 		_ = e.ToggleSuspend(params.DagID, params.Body.Value == "true")
 
 	case "stop":
-		if d.Status.Status != scheduler.StatusRunning {
+		if d.Status.Status != scheduler.StatusRunning && d.Status.Status != scheduler.StatusQueued {
 			return nil, response.NewBadRequestError(fmt.Errorf("the DAG is not running: %w", errInvalidArgs))
 		}
+		// The run with the request ID is stopped, or all the runs if it is empty.
 		e := h.engineFactory.Create()
-		if err := e.Stop(d.DAG); err != nil {
+		if err := e.Stop(d.DAG, params.Body.RequestID); err != nil {
 			return nil, response.NewBadRequestError(fmt.Errorf("error trying to stop the DAG: %w", err))
 		}
 
@@ -392,8 +412,22 @@ func (h *DAGHandler) PostAction(params operations.PostDagActionParams) (*models.
 		}
 
 	case "save":
+		if params.Body.Revision == "" {
+			return nil, response.NewBadRequestError(fmt.Errorf("revision is required: %w", errInvalidArgs))
+		}
 		e := h.engineFactory.Create()
-		err := e.UpdateDAG(params.DagID, params.Body.Value)
+		err := e.UpdateDAG(params.DagID, params.Body.Value, params.Body.Revision)
+		var verr *dag.ValidationError
+		if errors.As(err, &verr) {
+			return nil, response.NewValidationError(verr)
+		}
+		if errors.Is(err, persistence.ErrSpecConflict) {
+			spec, specErr := e.GetDAGSpec(params.DagID)
+			if specErr != nil {
+				return nil, response.NewInternalError(specErr)
+			}
+			return nil, response.NewConflictError(err, spec, persistence.SpecRevision([]byte(spec)))
+		}
 		if err != nil {
 			return nil, response.NewInternalError(err)
 		}
@@ -46,8 +46,13 @@ func ToDagDetail(d *dag.DAG) *models.DagDetail {
 		Location:          lo.ToPtr(d.Location),
 		LogDir:            lo.ToPtr(d.LogDir),
 		MaxActiveRuns:     lo.ToPtr(int64(d.MaxActiveRuns)),
+		MaxConcurrentRuns: lo.ToPtr(int64(d.MaxConcurrentRuns)),
+		OverlapPolicy:     string(d.OverlapPolicy),
 		Name:              lo.ToPtr(d.Name),
-		Params:            d.Params,
+		ParamSchema: lo.Map(d.ParamSchema, func(item dag.Param, _ int) *models.ParamSchema {
+			return ToParamSchema(item)
+		}),
+		Params: d.Params,
 		Preconditions: lo.Map(d.Preconditions, func(item *dag.Condition, _ int) *models.Condition {
 			return ToCondition(item)
 		}),
@@ -61,6 +66,17 @@ func ToDagDetail(d *dag.DAG) *models.DagDetail {
 	}
 }
 
+func ToParamSchema(param dag.Param) *models.ParamSchema {
+	return &models.ParamSchema{
+		Default:     param.Default,
+		Description: param.Description,
+		Name:        param.Name,
+		Required:    param.Required,
+		Type:        string(param.Type),
+		Values:      param.Values,
+	}
+}
+
 func ToHandlerOn(handlerOn dag.HandlerOn) *models.HandlerOn {
 	ret := &models.HandlerOn{}
 	if handlerOn.Failure != nil {
@@ -89,6 +105,7 @@ func ToDagStatusDetail(s *domain.Status) *models.DagStatusDetail {
 		FinishedAt: lo.ToPtr(s.FinishedAt),
 		Status:     lo.ToPtr(int64(s.Status)),
 		StatusText: lo.ToPtr(s.StatusText),
+		TimedOut:   s.TimedOut,
 		Nodes: lo.Map(s.Nodes, func(item *domain.Node, _ int) *models.StatusNode {
 			return ToNode(item)
 		}),
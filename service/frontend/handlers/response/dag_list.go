@@ -35,12 +35,14 @@ func ToDagListItem(s *persistence.DAGStatus) *models.DagListItem {
 
 func ToDAG(d *dag.DAG) *models.Dag {
 	return &models.Dag{
-		Name:          lo.ToPtr(d.Name),
-		Group:         lo.ToPtr(d.Group),
-		Description:   lo.ToPtr(d.Description),
-		Params:        d.Params,
-		DefaultParams: lo.ToPtr(d.DefaultParams),
-		Tags:          d.Tags,
+		Name:              lo.ToPtr(d.Name),
+		Group:             lo.ToPtr(d.Group),
+		Description:       lo.ToPtr(d.Description),
+		Params:            d.Params,
+		DefaultParams:     lo.ToPtr(d.DefaultParams),
+		Tags:              d.Tags,
+		MaxConcurrentRuns: lo.ToPtr(int64(d.MaxConcurrentRuns)),
+		OverlapPolicy:     string(d.OverlapPolicy),
 		Schedule: lo.Map(d.Schedule, func(item *dag.Schedule, _ int) *models.Schedule {
 			return ToSchedule(item)
 		}),
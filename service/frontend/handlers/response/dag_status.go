@@ -17,5 +17,6 @@ func ToDagStatus(s *domain.Status) *models.DagStatus {
 		FinishedAt: lo.ToPtr(s.FinishedAt),
 		Status:     lo.ToPtr(int64(s.Status)),
 		StatusText: lo.ToPtr(s.StatusText),
+		TimedOut:   s.TimedOut,
 	}
 }
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/service/frontend/models"
 	"github.com/samber/lo"
 )
@@ -43,3 +44,27 @@ func NewNotFoundError(err error) *CodedError {
 func NewBadRequestError(err error) *CodedError {
 	return NewCodedError(400, NewAPIError("Bad Request", err.Error()))
 }
+
+// NewValidationError returns a bad request error with the problems found in the DAG spec.
+func NewValidationError(err *dag.ValidationError) *CodedError {
+	apiError := NewAPIError("Invalid DAG spec", err.Error())
+	apiError.Errors = []*models.SpecError{}
+	for _, d := range err.Diagnostics {
+		apiError.Errors = append(apiError.Errors, &models.SpecError{
+			Line:     lo.ToPtr(int64(d.Line)),
+			Column:   lo.ToPtr(int64(d.Column)),
+			Severity: lo.ToPtr(string(d.Severity)),
+			Message:  lo.ToPtr(d.Message),
+		})
+	}
+	return NewCodedError(400, apiError)
+}
+
+// NewConflictError returns a conflict error with the current spec of the DAG
+// so that the client can show the difference from its edits.
+func NewConflictError(err error, spec, revision string) *CodedError {
+	apiError := NewAPIError("Conflict", err.Error())
+	apiError.Spec = spec
+	apiError.Revision = revision
+	return NewCodedError(409, apiError)
+}
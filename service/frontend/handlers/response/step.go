@@ -14,9 +14,11 @@ func ToStepObject(step dag.Step) *models.StepObject {
 		Depends:     step.Depends,
 		Description: lo.ToPtr(step.Description),
 		Dir:         lo.ToPtr(step.Dir),
+		GeneratedBy: step.GeneratedBy,
 		MailOnError: lo.ToPtr(step.MailOnError),
 		Name:        lo.ToPtr(step.Name),
 		Output:      lo.ToPtr(step.Output),
+		Pool:        step.Pool,
 		Preconditions: lo.Map(step.Preconditions, func(item *dag.Condition, _ int) *models.Condition {
 			return ToCondition(item)
 		}),
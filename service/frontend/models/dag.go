@@ -32,10 +32,17 @@ type Dag struct {
 	// Required: true
 	Group *string `json:"Group"`
 
+	// max concurrent runs
+	// Required: true
+	MaxConcurrentRuns *int64 `json:"MaxConcurrentRuns"`
+
 	// name
 	// Required: true
 	Name *string `json:"Name"`
 
+	// overlap policy
+	OverlapPolicy string `json:"OverlapPolicy,omitempty"`
+
 	// params
 	// Required: true
 	Params []string `json:"Params"`
@@ -65,6 +72,10 @@ func (m *Dag) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateMaxConcurrentRuns(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateName(formats); err != nil {
 		res = append(res, err)
 	}
@@ -114,6 +125,15 @@ func (m *Dag) validateGroup(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Dag) validateMaxConcurrentRuns(formats strfmt.Registry) error {
+
+	if err := validate.Required("MaxConcurrentRuns", "body", m.MaxConcurrentRuns); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *Dag) validateName(formats strfmt.Registry) error {
 
 	if err := validate.Required("Name", "body", m.Name); err != nil {
@@ -60,10 +60,20 @@ type DagDetail struct {
 	// Required: true
 	MaxActiveRuns *int64 `json:"MaxActiveRuns"`
 
+	// max concurrent runs
+	// Required: true
+	MaxConcurrentRuns *int64 `json:"MaxConcurrentRuns"`
+
 	// name
 	// Required: true
 	Name *string `json:"Name"`
 
+	// overlap policy
+	OverlapPolicy string `json:"OverlapPolicy,omitempty"`
+
+	// param schema
+	ParamSchema []*ParamSchema `json:"ParamSchema"`
+
 	// params
 	// Required: true
 	Params []string `json:"Params"`
@@ -129,10 +139,18 @@ func (m *DagDetail) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateMaxConcurrentRuns(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateName(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateParamSchema(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateParams(formats); err != nil {
 		res = append(res, err)
 	}
@@ -260,6 +278,15 @@ func (m *DagDetail) validateMaxActiveRuns(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *DagDetail) validateMaxConcurrentRuns(formats strfmt.Registry) error {
+
+	if err := validate.Required("MaxConcurrentRuns", "body", m.MaxConcurrentRuns); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *DagDetail) validateName(formats strfmt.Registry) error {
 
 	if err := validate.Required("Name", "body", m.Name); err != nil {
@@ -269,6 +296,32 @@ func (m *DagDetail) validateName(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *DagDetail) validateParamSchema(formats strfmt.Registry) error {
+	if swag.IsZero(m.ParamSchema) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.ParamSchema); i++ {
+		if swag.IsZero(m.ParamSchema[i]) { // not required
+			continue
+		}
+
+		if m.ParamSchema[i] != nil {
+			if err := m.ParamSchema[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("ParamSchema" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("ParamSchema" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *DagDetail) validateParams(formats strfmt.Registry) error {
 
 	if err := validate.Required("Params", "body", m.Params); err != nil {
@@ -376,6 +429,10 @@ func (m *DagDetail) ContextValidate(ctx context.Context, formats strfmt.Registry
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateParamSchema(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidatePreconditions(ctx, formats); err != nil {
 		res = append(res, err)
 	}
@@ -411,6 +468,31 @@ func (m *DagDetail) contextValidateHandlerOn(ctx context.Context, formats strfmt
 	return nil
 }
 
+func (m *DagDetail) contextValidateParamSchema(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.ParamSchema); i++ {
+
+		if m.ParamSchema[i] != nil {
+
+			if swag.IsZero(m.ParamSchema[i]) { // not required
+				return nil
+			}
+
+			if err := m.ParamSchema[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("ParamSchema" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("ParamSchema" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *DagDetail) contextValidatePreconditions(ctx context.Context, formats strfmt.Registry) error {
 
 	for i := 0; i < len(m.Preconditions); i++ {
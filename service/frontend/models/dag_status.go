@@ -54,6 +54,9 @@ type DagStatus struct {
 	// status text
 	// Required: true
 	StatusText *string `json:"StatusText"`
+
+	// timed out
+	TimedOut bool `json:"TimedOut,omitempty"`
 }
 
 // Validate validates this dag status
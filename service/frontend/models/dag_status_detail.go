@@ -75,6 +75,9 @@ type DagStatusDetail struct {
 	// status text
 	// Required: true
 	StatusText *string `json:"StatusText"`
+
+	// timed out
+	TimedOut bool `json:"TimedOut,omitempty"`
 }
 
 // Validate validates this dag status detail
@@ -7,6 +7,7 @@ package models
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -43,6 +44,12 @@ type GetDagDetailsResponse struct {
 	// Required: true
 	LogURL *string `json:"LogUrl"`
 
+	// revision
+	Revision string `json:"Revision,omitempty"`
+
+	// running statuses
+	RunningStatuses []*DagStatusDetail `json:"RunningStatuses"`
+
 	// sc log
 	// Required: true
 	ScLog *DagSchedulerLogResponse `json:"ScLog"`
@@ -88,6 +95,10 @@ func (m *GetDagDetailsResponse) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateRunningStatuses(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateScLog(formats); err != nil {
 		res = append(res, err)
 	}
@@ -186,6 +197,32 @@ func (m *GetDagDetailsResponse) validateLogURL(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *GetDagDetailsResponse) validateRunningStatuses(formats strfmt.Registry) error {
+	if swag.IsZero(m.RunningStatuses) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.RunningStatuses); i++ {
+		if swag.IsZero(m.RunningStatuses[i]) { // not required
+			continue
+		}
+
+		if m.RunningStatuses[i] != nil {
+			if err := m.RunningStatuses[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("RunningStatuses" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("RunningStatuses" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *GetDagDetailsResponse) validateScLog(formats strfmt.Registry) error {
 
 	if err := validate.Required("ScLog", "body", m.ScLog); err != nil {
@@ -256,6 +293,10 @@ func (m *GetDagDetailsResponse) ContextValidate(ctx context.Context, formats str
 		res = append(res, err)
 	}
 
+	if err := m.contextValidateRunningStatuses(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidateScLog(ctx, formats); err != nil {
 		res = append(res, err)
 	}
@@ -304,6 +345,31 @@ func (m *GetDagDetailsResponse) contextValidateLogData(ctx context.Context, form
 	return nil
 }
 
+func (m *GetDagDetailsResponse) contextValidateRunningStatuses(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.RunningStatuses); i++ {
+
+		if m.RunningStatuses[i] != nil {
+
+			if swag.IsZero(m.RunningStatuses[i]) { // not required
+				return nil
+			}
+
+			if err := m.RunningStatuses[i].ContextValidate(ctx, formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("RunningStatuses" + "." + strconv.Itoa(i))
+				} else if ce, ok := err.(*errors.CompositeError); ok {
+					return ce.ValidateName("RunningStatuses" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *GetDagDetailsResponse) contextValidateScLog(ctx context.Context, formats strfmt.Registry) error {
 
 	if m.ScLog != nil {
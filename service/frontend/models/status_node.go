@@ -35,6 +35,15 @@ type StatusNode struct {
 	// Required: true
 	Log *string `json:"Log"`
 
+	// next retry at
+	NextRetryAt string `json:"NextRetryAt,omitempty"`
+
+	// outputs
+	Outputs map[string]string `json:"Outputs,omitempty"`
+
+	// pool wait time
+	PoolWaitTime string `json:"PoolWaitTime,omitempty"`
+
 	// retry count
 	// Required: true
 	RetryCount *int64 `json:"RetryCount"`
@@ -54,6 +63,9 @@ type StatusNode struct {
 	// step
 	// Required: true
 	Step *StepObject `json:"Step"`
+
+	// timed out
+	TimedOut bool `json:"TimedOut,omitempty"`
 }
 
 // Validate validates this status node
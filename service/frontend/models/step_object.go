@@ -44,6 +44,9 @@ type StepObject struct {
 	// Required: true
 	Dir *string `json:"Dir"`
 
+	// generated by
+	GeneratedBy string `json:"GeneratedBy,omitempty"`
+
 	// mail on error
 	// Required: true
 	MailOnError *bool `json:"MailOnError"`
@@ -59,6 +62,9 @@ type StepObject struct {
 	// params
 	Params string `json:"Params,omitempty"`
 
+	// pool
+	Pool string `json:"Pool,omitempty"`
+
 	// preconditions
 	// Required: true
 	Preconditions []*Condition `json:"Preconditions"`
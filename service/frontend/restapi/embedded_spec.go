@@ -186,6 +186,9 @@ func init() {
                 "requestId": {
                   "type": "string"
                 },
+                "revision": {
+                  "type": "string"
+                },
                 "step": {
                   "type": "string"
                 },
@@ -281,8 +284,20 @@ func init() {
         "detailedMessage": {
           "type": "string"
         },
+        "errors": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/specError"
+          }
+        },
         "message": {
           "type": "string"
+        },
+        "revision": {
+          "type": "string"
+        },
+        "spec": {
+          "type": "string"
         }
       }
     },
@@ -315,6 +330,7 @@ func init() {
         "Name",
         "Schedule",
         "Description",
+        "MaxConcurrentRuns",
         "Params",
         "DefaultParams",
         "Tags"
@@ -329,9 +345,15 @@ func init() {
         "Group": {
           "type": "string"
         },
+        "MaxConcurrentRuns": {
+          "type": "integer"
+        },
         "Name": {
           "type": "string"
         },
+        "OverlapPolicy": {
+          "type": "string"
+        },
         "Params": {
           "type": "array",
           "items": {
@@ -368,6 +390,7 @@ func init() {
         "HistRetentionDays",
         "Preconditions",
         "MaxActiveRuns",
+        "MaxConcurrentRuns",
         "Params",
         "DefaultParams",
         "Tags"
@@ -406,9 +429,21 @@ func init() {
         "MaxActiveRuns": {
           "type": "integer"
         },
+        "MaxConcurrentRuns": {
+          "type": "integer"
+        },
         "Name": {
           "type": "string"
         },
+        "OverlapPolicy": {
+          "type": "string"
+        },
+        "ParamSchema": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/paramSchema"
+          }
+        },
         "Params": {
           "type": "array",
           "items": {
@@ -570,6 +605,9 @@ func init() {
         },
         "StatusText": {
           "type": "string"
+        },
+        "TimedOut": {
+          "type": "boolean"
         }
       }
     },
@@ -636,6 +674,9 @@ func init() {
         },
         "StatusText": {
           "type": "string"
+        },
+        "TimedOut": {
+          "type": "boolean"
         }
       }
     },
@@ -743,6 +784,15 @@ func init() {
         "LogUrl": {
           "type": "string"
         },
+        "Revision": {
+          "type": "string"
+        },
+        "RunningStatuses": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/dagStatusDetail"
+          }
+        },
         "ScLog": {
           "$ref": "#/definitions/dagSchedulerLogResponse"
         },
@@ -799,6 +849,32 @@ func init() {
         }
       }
     },
+    "paramSchema": {
+      "type": "object",
+      "properties": {
+        "Default": {
+          "type": "string"
+        },
+        "Description": {
+          "type": "string"
+        },
+        "Name": {
+          "type": "string"
+        },
+        "Required": {
+          "type": "boolean"
+        },
+        "Type": {
+          "type": "string"
+        },
+        "Values": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
     "postDagActionResponse": {
       "type": "object",
       "properties": {
@@ -881,6 +957,29 @@ func init() {
         }
       }
     },
+    "specError": {
+      "type": "object",
+      "required": [
+        "line",
+        "column",
+        "severity",
+        "message"
+      ],
+      "properties": {
+        "column": {
+          "type": "integer"
+        },
+        "line": {
+          "type": "integer"
+        },
+        "message": {
+          "type": "string"
+        },
+        "severity": {
+          "type": "string"
+        }
+      }
+    },
     "statusNode": {
       "type": "object",
       "required": [
@@ -907,6 +1006,18 @@ func init() {
         "Log": {
           "type": "string"
         },
+        "NextRetryAt": {
+          "type": "string"
+        },
+        "Outputs": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "PoolWaitTime": {
+          "type": "string"
+        },
         "RetryCount": {
           "type": "integer"
         },
@@ -921,6 +1032,9 @@ func init() {
         },
         "Step": {
           "$ref": "#/definitions/stepObject"
+        },
+        "TimedOut": {
+          "type": "boolean"
         }
       }
     },
@@ -968,6 +1082,9 @@ func init() {
         "Dir": {
           "type": "string"
         },
+        "GeneratedBy": {
+          "type": "string"
+        },
         "MailOnError": {
           "type": "boolean"
         },
@@ -980,6 +1097,9 @@ func init() {
         "Params": {
           "type": "string"
         },
+        "Pool": {
+          "type": "string"
+        },
         "Preconditions": {
           "type": "array",
           "items": {
@@ -1180,6 +1300,9 @@ func init() {
                 "requestId": {
                   "type": "string"
                 },
+                "revision": {
+                  "type": "string"
+                },
                 "step": {
                   "type": "string"
                 },
@@ -1275,8 +1398,20 @@ func init() {
         "detailedMessage": {
           "type": "string"
         },
+        "errors": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/specError"
+          }
+        },
         "message": {
           "type": "string"
+        },
+        "revision": {
+          "type": "string"
+        },
+        "spec": {
+          "type": "string"
         }
       }
     },
@@ -1309,6 +1444,7 @@ func init() {
         "Name",
         "Schedule",
         "Description",
+        "MaxConcurrentRuns",
         "Params",
         "DefaultParams",
         "Tags"
@@ -1323,9 +1459,15 @@ func init() {
         "Group": {
           "type": "string"
         },
+        "MaxConcurrentRuns": {
+          "type": "integer"
+        },
         "Name": {
           "type": "string"
         },
+        "OverlapPolicy": {
+          "type": "string"
+        },
         "Params": {
           "type": "array",
           "items": {
@@ -1362,6 +1504,7 @@ func init() {
         "HistRetentionDays",
         "Preconditions",
         "MaxActiveRuns",
+        "MaxConcurrentRuns",
         "Params",
         "DefaultParams",
         "Tags"
@@ -1400,9 +1543,21 @@ func init() {
         "MaxActiveRuns": {
           "type": "integer"
         },
+        "MaxConcurrentRuns": {
+          "type": "integer"
+        },
         "Name": {
           "type": "string"
         },
+        "OverlapPolicy": {
+          "type": "string"
+        },
+        "ParamSchema": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/paramSchema"
+          }
+        },
         "Params": {
           "type": "array",
           "items": {
@@ -1564,6 +1719,9 @@ func init() {
         },
         "StatusText": {
           "type": "string"
+        },
+        "TimedOut": {
+          "type": "boolean"
         }
       }
     },
@@ -1630,6 +1788,9 @@ func init() {
         },
         "StatusText": {
           "type": "string"
+        },
+        "TimedOut": {
+          "type": "boolean"
         }
       }
     },
@@ -1737,6 +1898,15 @@ func init() {
         "LogUrl": {
           "type": "string"
         },
+        "Revision": {
+          "type": "string"
+        },
+        "RunningStatuses": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/dagStatusDetail"
+          }
+        },
         "ScLog": {
           "$ref": "#/definitions/dagSchedulerLogResponse"
         },
@@ -1793,6 +1963,32 @@ func init() {
         }
       }
     },
+    "paramSchema": {
+      "type": "object",
+      "properties": {
+        "Default": {
+          "type": "string"
+        },
+        "Description": {
+          "type": "string"
+        },
+        "Name": {
+          "type": "string"
+        },
+        "Required": {
+          "type": "boolean"
+        },
+        "Type": {
+          "type": "string"
+        },
+        "Values": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
     "postDagActionResponse": {
       "type": "object",
       "properties": {
@@ -1875,6 +2071,29 @@ func init() {
         }
       }
     },
+    "specError": {
+      "type": "object",
+      "required": [
+        "line",
+        "column",
+        "severity",
+        "message"
+      ],
+      "properties": {
+        "column": {
+          "type": "integer"
+        },
+        "line": {
+          "type": "integer"
+        },
+        "message": {
+          "type": "string"
+        },
+        "severity": {
+          "type": "string"
+        }
+      }
+    },
     "statusNode": {
       "type": "object",
       "required": [
@@ -1901,6 +2120,18 @@ func init() {
         "Log": {
           "type": "string"
         },
+        "NextRetryAt": {
+          "type": "string"
+        },
+        "Outputs": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "PoolWaitTime": {
+          "type": "string"
+        },
         "RetryCount": {
           "type": "integer"
         },
@@ -1915,6 +2146,9 @@ func init() {
         },
         "Step": {
           "$ref": "#/definitions/stepObject"
+        },
+        "TimedOut": {
+          "type": "boolean"
         }
       }
     },
@@ -1962,6 +2196,9 @@ func init() {
         "Dir": {
           "type": "string"
         },
+        "GeneratedBy": {
+          "type": "string"
+        },
         "MailOnError": {
           "type": "boolean"
         },
@@ -1974,6 +2211,9 @@ func init() {
         "Params": {
           "type": "string"
         },
+        "Pool": {
+          "type": "string"
+        },
         "Preconditions": {
           "type": "array",
           "items": {
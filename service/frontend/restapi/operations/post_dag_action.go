@@ -77,6 +77,9 @@ type PostDagActionBody struct {
 	// request Id
 	RequestID string `json:"requestId,omitempty"`
 
+	// revision
+	Revision string `json:"revision,omitempty"`
+
 	// step
 	Step string `json:"step,omitempty"`
 
@@ -1,8 +1,10 @@
 package entry_reader
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +29,8 @@ type Params struct {
 	JobFactory    JobFactory
 	Logger        logger.Logger
 	EngineFactory engine.Factory
+	// CatchupWindow is how far back the missed runs are caught up.
+	CatchupWindow time.Duration
 }
 
 type EntryReader struct {
@@ -36,6 +40,7 @@ type EntryReader struct {
 	jf            JobFactory
 	logger        logger.Logger
 	engineFactory engine.Factory
+	catchupWindow time.Duration
 }
 
 func New(params Params) *EntryReader {
@@ -46,6 +51,7 @@ func New(params Params) *EntryReader {
 		jf:            params.JobFactory,
 		logger:        params.Logger,
 		engineFactory: params.EngineFactory,
+		catchupWindow: params.CatchupWindow,
 	}
 	if err := er.initDags(); err != nil {
 		er.logger.Error("failed to init entry_reader dags", tag.Error(err))
@@ -54,9 +60,94 @@ func New(params Params) *EntryReader {
 }
 
 func (er *EntryReader) Start(done chan any) {
+	er.catchUp(time.Now())
 	go er.watchDags(done)
 }
 
+// catchUp adds the runs of the DAGs with catchup enabled that were missed
+// since their last tick to the run queue. Only the runs within the catch-up
+// window before now are caught up, the missed runs are collapsed by the
+// overlap policy of the DAG, and the run at now is started by the scheduler
+// as usual.
+func (er *EntryReader) catchUp(now time.Time) {
+	now = now.Truncate(time.Minute)
+	er.dagsLock.Lock()
+	defer er.dagsLock.Unlock()
+
+	e := er.engineFactory.Create()
+	for _, d := range er.dags {
+		if !d.Catchup || e.IsSuspended(d.Name) {
+			continue
+		}
+		last, err := e.GetLastTick(d)
+		if err != nil {
+			er.logger.Error("failed to read the last tick", "dag", d.Name, tag.Error(err))
+			continue
+		}
+		if last.IsZero() {
+			// the DAG has never been scheduled
+			continue
+		}
+		ticks := missedTicks(d.Schedule, last, now, er.catchupWindow)
+		runs := collapseTicks(d, ticks)
+		if skipped := len(ticks) - len(runs); skipped > 0 {
+			er.logger.Info("skip missed runs", "dag", d.Name, "count", skipped, "policy", d.OverlapPolicy)
+		}
+		for _, t := range runs {
+			if err := e.StartScheduled(d, t); err != nil {
+				er.logger.Error("failed to catch up a missed run", "dag", d.Name, tag.Error(err))
+				break
+			}
+			er.logger.Info("catch up a missed run", "dag", d.Name, "time", t.Format("2006-01-02 15:04:05"))
+			if err := e.SetLastTick(d, t); err != nil {
+				er.logger.Error("failed to save the last tick", "dag", d.Name, tag.Error(err))
+				break
+			}
+		}
+	}
+}
+
+// collapseTicks returns the missed ticks that are caught up according to
+// the overlap policy of the DAG. The missed runs would overlap each other,
+// so only the latest one is caught up for skip and cancelPrevious, and at
+// most the run limit of the latest ones for queue. All of them are caught
+// up for allow.
+func collapseTicks(d *dag.DAG, ticks []time.Time) []time.Time {
+	n := len(ticks)
+	switch d.OverlapPolicy {
+	case dag.OverlapPolicyAllow:
+	case dag.OverlapPolicyQueue:
+		n = min(n, d.RunLimit())
+	default:
+		n = min(n, 1)
+	}
+	return ticks[len(ticks)-n:]
+}
+
+// missedTicks returns the schedule times after the last tick and before now
+// in ascending order. The times earlier than the window before now are
+// not returned.
+func missedTicks(schedules []*dag.Schedule, last, now time.Time, window time.Duration) []time.Time {
+	from := last
+	if start := now.Add(-window); from.Before(start) {
+		from = start
+	}
+	seen := map[int64]bool{}
+	var ret []time.Time
+	for _, s := range schedules {
+		for t := s.Parsed.Next(from); t.Before(now); t = s.Parsed.Next(t) {
+			if !seen[t.Unix()] {
+				seen[t.Unix()] = true
+				ret = append(ret, t)
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Before(ret[j])
+	})
+	return ret
+}
+
 func (er *EntryReader) Read(now time.Time) ([]*scheduler.Entry, error) {
 	var entries []*scheduler.Entry
 	er.dagsLock.Lock()
@@ -88,29 +179,53 @@ func (er *EntryReader) Read(now time.Time) ([]*scheduler.Entry, error) {
 	return entries, nil
 }
 
+// initDags reads all the DAGs in the DAGs directory. The DAGs read before
+// are replaced, so the DAGs whose files were removed are not scheduled.
 func (er *EntryReader) initDags() error {
-	er.dagsLock.Lock()
-	defer er.dagsLock.Unlock()
-	fis, err := os.ReadDir(er.dagsDir)
-	if err != nil {
-		return err
-	}
+	dags := map[string]*dag.DAG{}
 	var fileNames []string
-	for _, fi := range fis {
-		if util.MatchExtension(fi.Name(), dag.EXTENSIONS) {
-			d, err := dag.LoadMetadata(filepath.Join(er.dagsDir, fi.Name()))
-			if err != nil {
-				er.logger.Error("failed to read DAG cfg", tag.Error(err))
-				continue
+	// the DAGs are read from the folders of the DAGs directory as well
+	err := filepath.WalkDir(er.dagsDir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != er.dagsDir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
 			}
-			er.dags[fi.Name()] = d
-			fileNames = append(fileNames, fi.Name())
+			return nil
+		}
+		if !util.MatchExtension(entry.Name(), dag.EXTENSIONS) || dag.IsBaseConfig(entry.Name()) {
+			return nil
 		}
+		d, err := dag.LoadMetadata(file)
+		if err != nil {
+			er.logger.Error("failed to read DAG cfg", tag.Error(err))
+			return nil
+		}
+		name := er.entryName(file)
+		dags[name] = d
+		fileNames = append(fileNames, name)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	er.dagsLock.Lock()
+	er.dags = dags
+	er.dagsLock.Unlock()
 	er.logger.Info("init backend dags", "files", strings.Join(fileNames, ","))
 	return nil
 }
 
+// entryName returns the path of the DAG file relative to the DAGs directory.
+func (er *EntryReader) entryName(file string) string {
+	if rel, err := filepath.Rel(er.dagsDir, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(file)
+}
+
 func (er *EntryReader) watchDags(done chan any) {
 	watcher, err := filenotify.New(time.Minute)
 	if err != nil {
@@ -120,7 +235,9 @@ func (er *EntryReader) watchDags(done chan any) {
 	defer func() {
 		_ = watcher.Close()
 	}()
-	_ = watcher.Add(er.dagsDir)
+	if err := filenotify.AddRecursive(watcher, er.dagsDir); err != nil {
+		er.logger.Error("failed to watch DAGs directory", tag.Error(err))
+	}
 	for {
 		select {
 		case <-done:
@@ -130,20 +247,28 @@ func (er *EntryReader) watchDags(done chan any) {
 				return
 			}
 			if !util.MatchExtension(event.Name, dag.EXTENSIONS) {
+				er.handleDirEvent(watcher, event)
+				continue
+			}
+			if dag.IsBaseConfig(event.Name) {
+				// the base configuration applies to all the DAGs in the directory
+				if err := er.initDags(); err != nil {
+					er.logger.Error("failed to reload DAGs", tag.Error(err))
+				}
 				continue
 			}
 			er.dagsLock.Lock()
 			if event.Op == fsnotify.Create || event.Op == fsnotify.Write {
-				d, err := dag.LoadMetadata(filepath.Join(er.dagsDir, filepath.Base(event.Name)))
+				d, err := dag.LoadMetadata(event.Name)
 				if err != nil {
 					er.logger.Error("failed to read DAG cfg", tag.Error(err))
 				} else {
-					er.dags[filepath.Base(event.Name)] = d
+					er.dags[er.entryName(event.Name)] = d
 					er.logger.Info("reload DAG entry_reader", "file", event.Name)
 				}
 			}
 			if event.Op == fsnotify.Rename || event.Op == fsnotify.Remove {
-				delete(er.dags, filepath.Base(event.Name))
+				delete(er.dags, er.entryName(event.Name))
 				er.logger.Info("remove DAG entry_reader", "file", event.Name)
 			}
 			er.dagsLock.Unlock()
@@ -156,3 +281,30 @@ func (er *EntryReader) watchDags(done chan any) {
 	}
 
 }
+
+// handleDirEvent watches a folder created in the DAGs directory and reads
+// the DAGs in it, and it removes the DAGs of a folder that was removed.
+func (er *EntryReader) handleDirEvent(watcher filenotify.FileWatcher, event fsnotify.Event) {
+	switch {
+	case event.Op == fsnotify.Create:
+		if fi, err := os.Stat(event.Name); err != nil || !fi.IsDir() {
+			return
+		}
+		if err := filenotify.AddRecursive(watcher, event.Name); err != nil {
+			er.logger.Error("failed to watch DAGs folder", tag.Error(err))
+		}
+		if err := er.initDags(); err != nil {
+			er.logger.Error("failed to reload DAGs", tag.Error(err))
+		}
+	case event.Op == fsnotify.Rename || event.Op == fsnotify.Remove:
+		prefix := er.entryName(event.Name) + "/"
+		er.dagsLock.Lock()
+		defer er.dagsLock.Unlock()
+		for name := range er.dags {
+			if strings.HasPrefix(name, prefix) {
+				delete(er.dags, name)
+				er.logger.Info("remove DAG entry_reader", "file", name)
+			}
+		}
+	}
+}
@@ -104,6 +104,144 @@ func TestReadEntries(t *testing.T) {
 	require.Equal(t, len(entries)-1, len(lives))
 }
 
+func TestReadEntriesInFolders(t *testing.T) {
+	tmpDir, ef := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	dagsDir := path.Join(tmpDir, "dags")
+	cfg := config.Get()
+	cfgDAGs := cfg.DAGs
+	cfg.DAGs = dagsDir
+	defer func() {
+		cfg.DAGs = cfgDAGs
+	}()
+
+	spec := []byte("schedule: \"* * * * *\"\nsteps:\n  - name: a\n    command: \"true\"\n")
+	require.NoError(t, os.MkdirAll(path.Join(dagsDir, "team"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dagsDir, "team", "job.yaml"), spec, 0600))
+
+	er := New(Params{
+		DagsDir:       dagsDir,
+		JobFactory:    &mockJobFactory{},
+		Logger:        logger.NewSlogLogger(),
+		EngineFactory: ef,
+	})
+	done := make(chan any)
+	defer close(done)
+	er.Start(done)
+
+	now := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+	entries, err := er.Read(now)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "team", entries[0].Job.GetDAG().Group)
+
+	// the DAGs in a new folder are read by the watcher
+	time.Sleep(time.Millisecond * 100)
+	require.NoError(t, os.MkdirAll(path.Join(dagsDir, "other"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dagsDir, "other", "job2.yaml"), spec, 0600))
+	require.Eventually(t, func() bool {
+		entries, err := er.Read(now)
+		return err == nil && len(entries) == 2
+	}, time.Second*5, time.Millisecond*100)
+}
+
+func TestInitDags(t *testing.T) {
+	tmpDir, ef := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	dagsDir := path.Join(tmpDir, "dags")
+	require.NoError(t, os.MkdirAll(dagsDir, 0755))
+	spec := []byte("schedule: \"* * * * *\"\nsteps:\n  - name: a\n    command: \"true\"\n")
+	for _, name := range []string{"job1.yaml", "job2.yaml"} {
+		require.NoError(t, os.WriteFile(path.Join(dagsDir, name), spec, 0600))
+	}
+
+	er := New(Params{
+		DagsDir:       dagsDir,
+		JobFactory:    &mockJobFactory{},
+		Logger:        logger.NewSlogLogger(),
+		EngineFactory: ef,
+	})
+	now := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+	entries, err := er.Read(now)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// the DAG whose file was removed is not scheduled after reloading
+	require.NoError(t, os.Remove(path.Join(dagsDir, "job2.yaml")))
+	require.NoError(t, er.initDags())
+	entries, err = er.Read(now)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "job1", entries[0].Job.GetDAG().Name)
+}
+
+func TestCatchUp(t *testing.T) {
+	tmpDir, ef := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	dagsDir := path.Join(tmpDir, "dags")
+	require.NoError(t, os.MkdirAll(dagsDir, 0755))
+	for name, spec := range map[string]string{
+		"catchup.yaml":       "catchup: true\nschedule:\n  - \"0 * * * *\"\n  - \"0 */2 * * *\"\nsteps:\n  - name: a\n    command: \"true\"\n",
+		"catchup_queue.yaml": "catchup: true\noverlapPolicy: queue\nmaxConcurrentRuns: 2\nschedule: \"0 * * * *\"\nsteps:\n  - name: a\n    command: \"true\"\n",
+		"catchup_allow.yaml": "catchup: true\noverlapPolicy: allow\nschedule: \"0 * * * *\"\nsteps:\n  - name: a\n    command: \"true\"\n",
+		"no_catchup.yaml":    "schedule: \"0 * * * *\"\nsteps:\n  - name: a\n    command: \"true\"\n",
+	} {
+		require.NoError(t, os.WriteFile(path.Join(dagsDir, name), []byte(spec), 0600))
+	}
+
+	er := New(Params{
+		DagsDir:       dagsDir,
+		JobFactory:    &mockJobFactory{},
+		Logger:        logger.NewSlogLogger(),
+		EngineFactory: ef,
+		CatchupWindow: time.Hour * 4,
+	})
+
+	e := ef.Create()
+	now := time.Date(2020, 1, 1, 10, 30, 0, 0, time.Local)
+	for _, d := range er.dags {
+		require.NoError(t, e.SetLastTick(d, now.Add(-time.Hour*5)))
+	}
+
+	er.catchUp(now)
+
+	// the runs from 7:00 to 10:00 are within the window, and they are
+	// collapsed by the overlap policy
+	expected := map[string]int{
+		"catchup":       1,
+		"catchup_queue": 2,
+		"catchup_allow": 4,
+		"no_catchup":    0,
+	}
+	for _, d := range er.dags {
+		queued, err := e.GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Len(t, queued, expected[d.Name], d.Name)
+		if d.Catchup {
+			tick, err := e.GetLastTick(d)
+			require.NoError(t, err)
+			require.True(t, now.Add(-time.Minute*30).Equal(tick))
+		}
+	}
+
+	// the runs are caught up only once
+	er.catchUp(now)
+	for _, d := range er.dags {
+		queued, err := e.GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Len(t, queued, expected[d.Name], d.Name)
+	}
+}
+
 type mockJobFactory struct{}
 
 func (f *mockJobFactory) NewJob(d *dag.DAG, next time.Time) scheduler.Job {
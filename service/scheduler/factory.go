@@ -5,6 +5,7 @@ import (
 
 	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/internal/engine"
+	"github.com/dagu-dev/dagu/internal/logger"
 	"github.com/dagu-dev/dagu/service/scheduler/job"
 	"github.com/dagu-dev/dagu/service/scheduler/scheduler"
 )
@@ -13,6 +14,7 @@ type jobFactory struct {
 	Executable    string
 	WorkDir       string
 	EngineFactory engine.Factory
+	Logger        logger.Logger
 }
 
 func (jf jobFactory) NewJob(d *dag.DAG, next time.Time) scheduler.Job {
@@ -22,5 +24,6 @@ func (jf jobFactory) NewJob(d *dag.DAG, next time.Time) scheduler.Job {
 		WorkDir:       jf.WorkDir,
 		Next:          next,
 		EngineFactory: jf.EngineFactory,
+		Logger:        jf.Logger,
 	}
 }
@@ -7,7 +7,11 @@
 package filenotify
 
 import (
+	"errors"
+	"io/fs"
 	"log"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -22,6 +26,26 @@ type FileWatcher interface {
 	Close() error
 }
 
+// AddRecursive adds the directory and all of its subdirectories to the watcher.
+// Hidden subdirectories are skipped.
+func AddRecursive(w FileWatcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if p != dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := w.Add(p); err != nil && !errors.Is(err, errWatchExists) {
+			return err
+		}
+		return nil
+	})
+}
+
 // New tries to use an fs-event watcher, and falls back to the poller if there is an error
 func New(interval time.Duration) (FileWatcher, error) {
 	if watcher, err := NewEventWatcher(); err == nil {
@@ -33,17 +33,19 @@ func EntryReaderProvider(
 	return entry_reader.New(entry_reader.Params{
 		EngineFactory: engineFactory,
 		// TODO: fix this
-		DagsDir:    cfg.DAGs,
-		JobFactory: jf,
-		Logger:     logger,
+		DagsDir:       cfg.DAGs,
+		JobFactory:    jf,
+		Logger:        logger,
+		CatchupWindow: cfg.CatchupWindow,
 	})
 }
 
-func JobFactoryProvider(cfg *config.Config, engineFactory engine.Factory) entry_reader.JobFactory {
+func JobFactoryProvider(cfg *config.Config, engineFactory engine.Factory, logger dagulogger.Logger) entry_reader.JobFactory {
 	return &jobFactory{
 		WorkDir:       cfg.WorkDir,
 		EngineFactory: engineFactory,
 		Executable:    cfg.Executable,
+		Logger:        logger,
 	}
 }
 
@@ -56,14 +58,16 @@ func New(params Params) *scheduler.Scheduler {
 	})
 }
 
-func LifetimeHooks(lc fx.Lifecycle, a *scheduler.Scheduler) {
+func LifetimeHooks(lc fx.Lifecycle, a *scheduler.Scheduler, dp *engine.Dispatcher) {
 	lc.Append(
 		fx.Hook{
 			OnStart: func(ctx context.Context) (err error) {
+				dp.Start()
 				return a.Start()
 			},
 			OnStop: func(_ context.Context) error {
 				a.Stop()
+				dp.Stop()
 				return nil
 			},
 		},
@@ -6,21 +6,22 @@ import (
 
 	"github.com/dagu-dev/dagu/internal/dag"
 	"github.com/dagu-dev/dagu/internal/engine"
+	"github.com/dagu-dev/dagu/internal/logger"
+	"github.com/dagu-dev/dagu/internal/persistence/model"
 	"github.com/dagu-dev/dagu/internal/scheduler"
 	"github.com/dagu-dev/dagu/internal/util"
 )
 
-// TODO: write tests
 type Job struct {
 	DAG           *dag.DAG
 	Executable    string
 	WorkDir       string
 	Next          time.Time
 	EngineFactory engine.Factory
+	Logger        logger.Logger
 }
 
 var (
-	ErrJobRunning      = errors.New("job already running")
 	ErrJobIsNotRunning = errors.New("job is not running")
 	ErrJobFinished     = errors.New("job already finished")
 )
@@ -31,14 +32,35 @@ func (j *Job) GetDAG() *dag.DAG {
 
 func (j *Job) Start() error {
 	e := j.EngineFactory.Create()
-	s, err := e.GetLatestStatus(j.DAG)
+	err := j.start(e)
+	if j.DAG.Catchup && (err == nil || errors.Is(err, ErrJobFinished)) {
+		// The run is enqueued or skipped, so the runs before this tick are
+		// not caught up anymore. The tick is not saved when the run failed
+		// to be enqueued so that it is caught up later.
+		if err := e.SetLastTick(j.DAG, j.Next); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (j *Job) start(e engine.Engine) error {
+	running, err := e.GetRunningStatuses(j.DAG)
 	if err != nil {
 		return err
 	}
+	queued, err := e.GetQueuedStatuses(j.DAG)
+	if err != nil {
+		return err
+	}
+	if len(running)+len(queued) >= j.DAG.RunLimit() {
+		// already running or queued as many as the DAG allows
+		return j.overlap(e, queued)
+	}
 
-	if s.Status == scheduler.StatusRunning {
-		// already running
-		return ErrJobRunning
+	s, err := e.GetLatestStatus(j.DAG)
+	if err != nil {
+		return err
 	}
 
 	// check the last execution time
@@ -49,8 +71,36 @@ func (j *Job) Start() error {
 			return ErrJobFinished
 		}
 	}
-	// should not be here
-	return e.Start(j.DAG, "")
+	// the run is started by the dispatcher
+	return e.StartScheduled(j.DAG, j.Next)
+}
+
+// overlap handles the scheduled run while the DAG is running as many runs
+// as it allows, according to the overlap policy of the DAG.
+func (j *Job) overlap(e engine.Engine, queued []*model.Status) error {
+	switch j.DAG.OverlapPolicy {
+	case dag.OverlapPolicyQueue:
+		// At most as many runs as the DAG allows wait in the queue so that
+		// the runs do not pile up when the DAG runs longer than its interval.
+		if len(queued) < j.DAG.RunLimit() {
+			j.Logger.Info("queue the scheduled run until the current run finishes", "job", j.String())
+			// the dispatcher starts the run when a current run finishes
+			return e.StartScheduled(j.DAG, j.Next)
+		}
+	case dag.OverlapPolicyCancelPrevious:
+		j.Logger.Info("cancel the current runs for the scheduled run", "job", j.String())
+		if err := e.Stop(j.DAG, ""); err != nil {
+			return err
+		}
+		// the dispatcher starts the run when the canceled runs finish
+		return e.StartScheduled(j.DAG, j.Next)
+	case dag.OverlapPolicyAllow:
+		j.Logger.Info("queue the scheduled run in parallel with the current runs", "job", j.String())
+		// the dispatcher starts the run while fewer runs than the DAG allows are running
+		return e.StartScheduled(j.DAG, j.Next)
+	}
+	j.Logger.Warn("skip the scheduled run because the DAG is running", "job", j.String(), "time", j.Next.Format("2006-01-02 15:04:05"))
+	return e.RecordSkipped(j.DAG, j.Next)
 }
 
 func (j *Job) Stop() error {
@@ -62,7 +112,7 @@ func (j *Job) Stop() error {
 	if s.Status != scheduler.StatusRunning {
 		return ErrJobIsNotRunning
 	}
-	return e.Stop(j.DAG)
+	return e.Stop(j.DAG, "")
 }
 
 func (j *Job) Restart() error {
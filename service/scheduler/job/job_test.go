@@ -0,0 +1,226 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dagu-dev/dagu/internal/config"
+	"github.com/dagu-dev/dagu/internal/dag"
+	"github.com/dagu-dev/dagu/internal/engine"
+	"github.com/dagu-dev/dagu/internal/logger"
+	"github.com/dagu-dev/dagu/internal/persistence/client"
+	"github.com/dagu-dev/dagu/internal/persistence/model"
+	"github.com/dagu-dev/dagu/internal/scheduler"
+	"github.com/dagu-dev/dagu/internal/sock"
+	"github.com/dagu-dev/dagu/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T) (string, engine.Factory) {
+	t.Helper()
+
+	tmpDir := util.MustTempDir("dagu_test")
+	_ = os.Setenv("HOME", tmpDir)
+	_ = config.LoadConfig()
+
+	ds := client.NewDataStoreFactory(&config.Config{
+		DataDir: path.Join(tmpDir, ".dagu", "data"),
+		DAGs:    tmpDir,
+	})
+
+	return tmpDir, engine.NewFactory(ds, &config.Config{})
+}
+
+// testRunningDAG loads a DAG with the overlap policy and serves the status
+// of a running run of it. It returns the channel that receives a value when
+// the run is stopped.
+func testRunningDAG(t *testing.T, tmpDir string, policy dag.OverlapPolicy) (*dag.DAG, chan struct{}) {
+	t.Helper()
+
+	file := path.Join(tmpDir, fmt.Sprintf("overlap_%s.yaml", policy))
+	spec := fmt.Sprintf("overlapPolicy: %s\nsteps:\n  - name: \"1\"\n    command: \"true\"\n", policy)
+	require.NoError(t, os.WriteFile(file, []byte(spec), 0644))
+	d, err := dag.LoadMetadata(file)
+	require.NoError(t, err)
+
+	stopped := make(chan struct{}, 1)
+	server, err := sock.NewServer(&sock.Config{
+		Addr: d.SockAddr("running"),
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				stopped <- struct{}{}
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte("OK"))
+				return
+			}
+			status := model.NewStatus(d, nil, scheduler.StatusRunning, 0, nil, nil)
+			status.RequestId = "running"
+			w.WriteHeader(http.StatusOK)
+			b, _ := status.ToJson()
+			_, _ = w.Write(b)
+		},
+	})
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(nil)
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+	time.Sleep(time.Millisecond * 100)
+	return d, stopped
+}
+
+func TestJob_Start(t *testing.T) {
+	next := time.Now().Truncate(time.Minute)
+
+	t.Run("Skip", func(t *testing.T) {
+		tmpDir, ef := setupTest(t)
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+		d, _ := testRunningDAG(t, tmpDir, dag.OverlapPolicySkip)
+		j := &Job{DAG: d, Next: next, EngineFactory: ef, Logger: logger.NewSlogLogger()}
+
+		require.NoError(t, j.Start())
+
+		e := ef.Create()
+		queued, err := e.GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Empty(t, queued)
+
+		// the skipped run is recorded in the history
+		history := e.GetRecentHistory(d, 1)
+		require.Len(t, history, 1)
+		require.Equal(t, scheduler.StatusSkipped, history[0].Status.Status)
+		require.Equal(t, util.FormatTime(next), history[0].Status.StartedAt)
+	})
+	t.Run("Queue", func(t *testing.T) {
+		tmpDir, ef := setupTest(t)
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+		d, _ := testRunningDAG(t, tmpDir, dag.OverlapPolicyQueue)
+		j := &Job{DAG: d, Next: next, EngineFactory: ef, Logger: logger.NewSlogLogger()}
+
+		require.NoError(t, j.Start())
+
+		e := ef.Create()
+		queued, err := e.GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+
+		// the next run is skipped because a run is already waiting
+		require.NoError(t, j.Start())
+		queued, err = e.GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+		history := e.GetRecentHistory(d, 1)
+		require.Len(t, history, 1)
+		require.Equal(t, scheduler.StatusSkipped, history[0].Status.Status)
+	})
+	t.Run("CancelPrevious", func(t *testing.T) {
+		tmpDir, ef := setupTest(t)
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+		d, stopped := testRunningDAG(t, tmpDir, dag.OverlapPolicyCancelPrevious)
+		j := &Job{DAG: d, Next: next, EngineFactory: ef, Logger: logger.NewSlogLogger()}
+
+		require.NoError(t, j.Start())
+		require.Len(t, stopped, 1)
+
+		queued, err := ef.Create().GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+	})
+	t.Run("Allow", func(t *testing.T) {
+		tmpDir, ef := setupTest(t)
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+		d, _ := testRunningDAG(t, tmpDir, dag.OverlapPolicyAllow)
+		j := &Job{DAG: d, Next: next, EngineFactory: ef, Logger: logger.NewSlogLogger()}
+
+		require.NoError(t, j.Start())
+		require.NoError(t, j.Start())
+
+		queued, err := ef.Create().GetQueuedStatuses(d)
+		require.NoError(t, err)
+		require.Len(t, queued, 2)
+	})
+}
+
+func TestJob_StartCatchup(t *testing.T) {
+	tmpDir, ef := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	file := path.Join(tmpDir, "catchup.yaml")
+	spec := "catchup: true\nsteps:\n  - name: \"1\"\n    command: \"true\"\n"
+	require.NoError(t, os.WriteFile(file, []byte(spec), 0644))
+	d, err := dag.LoadMetadata(file)
+	require.NoError(t, err)
+
+	next := time.Now().Truncate(time.Minute)
+	j := &Job{DAG: d, Next: next, EngineFactory: ef, Logger: logger.NewSlogLogger()}
+	require.NoError(t, j.Start())
+
+	// the tick is saved to catch up the runs after it
+	e := ef.Create()
+	tick, err := e.GetLastTick(d)
+	require.NoError(t, err)
+	require.True(t, next.Equal(tick))
+
+	queued, err := e.GetQueuedStatuses(d)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+}
+
+type errEngineFactory struct {
+	engine.Factory
+	err error
+}
+
+func (f *errEngineFactory) Create() engine.Engine {
+	return &errEngine{Engine: f.Factory.Create(), err: f.err}
+}
+
+// errEngine is an engine that fails to enqueue the scheduled runs.
+type errEngine struct {
+	engine.Engine
+	err error
+}
+
+func (e *errEngine) StartScheduled(_ *dag.DAG, _ time.Time) error {
+	return e.err
+}
+
+func TestJob_StartCatchupError(t *testing.T) {
+	tmpDir, ef := setupTest(t)
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	file := path.Join(tmpDir, "catchup.yaml")
+	spec := "catchup: true\nsteps:\n  - name: \"1\"\n    command: \"true\"\n"
+	require.NoError(t, os.WriteFile(file, []byte(spec), 0644))
+	d, err := dag.LoadMetadata(file)
+	require.NoError(t, err)
+
+	errStart := errors.New("failed to enqueue")
+	next := time.Now().Truncate(time.Minute)
+	j := &Job{DAG: d, Next: next, EngineFactory: &errEngineFactory{Factory: ef, err: errStart}, Logger: logger.NewSlogLogger()}
+	require.ErrorIs(t, j.Start(), errStart)
+
+	// the tick is not saved so that the run is caught up later
+	tick, err := ef.Create().GetLastTick(d)
+	require.NoError(t, err)
+	require.True(t, tick.IsZero())
+}
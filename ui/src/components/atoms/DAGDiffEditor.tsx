@@ -0,0 +1,22 @@
+import React from 'react';
+import { MonacoDiffEditor } from 'react-monaco-editor';
+
+type Props = {
+  original: string;
+  value: string;
+  onChange: (value: string) => void;
+};
+
+function DAGDiffEditor({ original, value, onChange }: Props) {
+  return (
+    <MonacoDiffEditor
+      height="60vh"
+      original={original}
+      value={value}
+      onChange={onChange}
+      language="yaml"
+    />
+  );
+}
+
+export default DAGDiffEditor;
@@ -1,17 +1,48 @@
 import React from 'react';
-import MonacoEditor from 'react-monaco-editor';
+import MonacoEditor, { EditorDidMount } from 'react-monaco-editor';
+import * as monaco from 'monaco-editor';
+import { SpecError } from '../../models/api';
 
 type Props = {
   value: string;
   onChange: (value: string) => void;
+  errors?: SpecError[];
 };
 
-function DAGEditor({ value, onChange }: Props) {
+function DAGEditor({ value, onChange, errors }: Props) {
+  const editorRef = React.useRef<monaco.editor.IStandaloneCodeEditor>();
+  const onMount: EditorDidMount = (editor) => {
+    editorRef.current = editor;
+  };
+  React.useEffect(() => {
+    const model = editorRef.current?.getModel();
+    if (!model) {
+      return;
+    }
+    monaco.editor.setModelMarkers(
+      model,
+      'dagu',
+      (errors || [])
+        .filter((e) => e.line > 0)
+        .map((e) => ({
+          startLineNumber: e.line,
+          startColumn: e.column,
+          endLineNumber: e.line,
+          endColumn: model.getLineMaxColumn(e.line),
+          message: e.message,
+          severity:
+            e.severity == 'error'
+              ? monaco.MarkerSeverity.Error
+              : monaco.MarkerSeverity.Warning,
+        }))
+    );
+  }, [errors]);
   return (
     <MonacoEditor
       height="60vh"
       value={value}
       onChange={onChange}
+      editorDidMount={onMount}
       language="yaml"
     />
   );
@@ -28,7 +28,9 @@ function CreateDAGButton() {
           }),
         });
         if (resp.ok) {
-          window.location.href = `/dags/${name.replace(/.yaml$/, '')}/spec`;
+          window.location.href = `/dags/${encodeURIComponent(
+            name.replace(/.yaml$/, '')
+          )}/spec`;
         } else {
           const e = await resp.text();
           alert(e);
@@ -51,7 +51,9 @@ function DAGActions({
       requestId?: string;
       params?: string;
     }) => {
-      const url = `${getConfig().apiURL}/dags/${params.name}`;
+      const url = `${getConfig().apiURL}/dags/${encodeURIComponent(
+        params.name
+      )}`;
       const ret = await fetch(url, {
         method: 'POST',
         headers: {
@@ -76,12 +78,20 @@ function DAGActions({
 
   const buttonState = React.useMemo(
     () => ({
-      start: status?.Status != SchedulerStatus.Running,
-      stop: status?.Status == SchedulerStatus.Running,
+      start:
+        (status?.Status != SchedulerStatus.Running &&
+          status?.Status != SchedulerStatus.Queued) ||
+        dag.MaxConcurrentRuns > 1,
+      stop:
+        status?.Status == SchedulerStatus.Running ||
+        status?.Status == SchedulerStatus.Queued,
       retry:
-        status?.Status != SchedulerStatus.Running && status?.RequestId != '',
+        status?.Status != SchedulerStatus.Running &&
+        status?.Status != SchedulerStatus.Queued &&
+        status?.Status != SchedulerStatus.Skipped &&
+        status?.RequestId != '',
     }),
-    [status]
+    [status, dag]
   );
   return (
     <Stack direction="row" spacing={2}>
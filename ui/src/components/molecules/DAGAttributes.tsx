@@ -35,6 +35,10 @@ function DAGAttributes({ dag: config }: Props) {
       </LabeledItem>
       <LabeledItem label="Description">{config.Description}</LabeledItem>
       <LabeledItem label="Max Active Runs">{config.MaxActiveRuns}</LabeledItem>
+      <LabeledItem label="Max Concurrent Runs">
+        {config.MaxConcurrentRuns}
+      </LabeledItem>
+      <LabeledItem label="Overlap Policy">{config.OverlapPolicy}</LabeledItem>
       <LabeledItem label="Params">{config.Params?.join(' ')}</LabeledItem>
       <Stack direction={'column'}>
         <React.Fragment>
@@ -17,7 +17,9 @@ function DAGErrors({ DAGs, errors, hasError }: Props) {
       <div className="content">
         <ul>
           {DAGs.filter((w) => w.ErrorT).map((w) => {
-            const url = encodeURI(w.File);
+            const url = `/dags/${encodeURIComponent(
+              w.File.replace(/.y[a]{0,1}ml$/, '')
+            )}`;
             return (
               <li>
                 <a href={url}>{w.File}</a>: {w.ErrorT}{' '}
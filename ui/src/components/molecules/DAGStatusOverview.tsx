@@ -12,14 +12,18 @@ type Props = {
 };
 
 function DAGStatusOverview({ status, name, file = '' }: Props) {
-  const url = `/dags/${name}/scheduler-log?&file=${encodeURI(file)}`;
+  const url = `/dags/${encodeURIComponent(
+    name
+  )}/scheduler-log?&file=${encodeURI(file)}`;
   if (!status) {
     return null;
   }
   return (
     <Stack direction="column" spacing={1}>
       <LabeledItem label="Status">
-        <StatusChip status={status.Status}>{status.StatusText}</StatusChip>
+        <StatusChip status={status.Status}>
+          {status.TimedOut ? 'timed out' : status.StatusText}
+        </StatusChip>
       </LabeledItem>
       <LabeledItem label="Request ID">{status.RequestId}</LabeledItem>
       <Stack direction="row" sx={{ alignItems: 'center' }} spacing={2}>
@@ -73,6 +73,7 @@ function Graph({
     dat.push('classDef cancel color:#333,fill:white,stroke:pink,stroke-width:1.2px');
     dat.push('classDef done color:#333,fill:white,stroke:green,stroke-width:1.2px');
     dat.push('classDef skipped color:#333,fill:white,stroke:gray,stroke-width:1.2px');
+    dat.push('classDef waiting color:#333,fill:white,stroke:gold,stroke-width:1.2px');
     return dat.join('\n');
   }, [steps, onClickNode, flowchart]);
   return <Mermaid style={mermaidStyle} def={graph} />;
@@ -87,4 +88,5 @@ const graphStatusMap = {
   [NodeStatus.Cancel]: ':::cancel',
   [NodeStatus.Success]: ':::done',
   [NodeStatus.Skipped]: ':::skipped',
+  [NodeStatus.Waiting]: ':::waiting',
 };
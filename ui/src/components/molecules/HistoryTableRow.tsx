@@ -1,7 +1,8 @@
 import { TableCell } from '@mui/material';
 import React, { CSSProperties } from 'react';
 import { GridData } from '../../models/api';
-import { statusColorMapping } from '../../consts';
+import { nodeStatusColorMapping } from '../../consts';
+import { NodeStatus } from '../../models';
 import StyledTableRow from '../atoms/StyledTableRow';
 
 type Props = {
@@ -24,8 +25,9 @@ function HistoryTableRow({ data, onSelect, idx }: Props) {
           tdStyle.backgroundColor = '#FFDDAD';
         }
         if (status != 0) {
-          style.backgroundColor = statusColorMapping[status].backgroundColor;
-          style.color = statusColorMapping[status].color;
+          const colors = nodeStatusColorMapping[status as NodeStatus];
+          style.backgroundColor = colors.backgroundColor;
+          style.color = colors.color;
         }
         return (
           <TableCell
@@ -22,7 +22,7 @@ function NodeStatusTableRow({
   file,
   onRequireModal,
 }: Props) {
-  const url = `/dags/${name}/log?file=${file}&step=${node.Step.Name}`;
+  const url = `/dags/${encodeURIComponent(name)}/log?file=${file}&step=${node.Step.Name}`;
   const buttonStyle = {
     margin: '0px',
     padding: '0px',
@@ -36,19 +36,35 @@ function NodeStatusTableRow({
       <TableCell> {node.Step.Name} </TableCell>
       <TableCell>
         <MultilineText>{node.Step.Description}</MultilineText>
+        {node.Step.GeneratedBy ? ` (generated by ${node.Step.GeneratedBy})` : ''}
       </TableCell>
       <TableCell> {node.Step.Command} </TableCell>
       <TableCell> {node.Step.Args ? node.Step.Args.join(' ') : ''} </TableCell>
-      <TableCell> {node.StartedAt} </TableCell>
+      <TableCell>
+        {node.StartedAt}
+        {node.PoolWaitTime
+          ? ` (waited ${node.PoolWaitTime} for pool ${node.Step.Pool})`
+          : ''}
+      </TableCell>
       <TableCell> {node.FinishedAt} </TableCell>
       <TableCell>
         <button style={buttonStyle} onClick={() => onRequireModal(node.Step)}>
           <NodeStatusChip status={node.Status}>
-            {node.StatusText}
+            {node.TimedOut ? 'timed out' : node.StatusText}
+            {node.NextRetryAt ? ` (retrying at ${node.NextRetryAt})` : ''}
           </NodeStatusChip>
         </button>
       </TableCell>
-      <TableCell> {node.Error} </TableCell>
+      <TableCell>
+        {node.Error}
+        {node.Outputs ? (
+          <MultilineText>
+            {Object.entries(node.Outputs)
+              .map(([key, value]) => `${key}=${value}`)
+              .join('\n')}
+          </MultilineText>
+        ) : null}
+      </TableCell>
       <TableCell>
         {node.Log ? (
           <Link to={url}>
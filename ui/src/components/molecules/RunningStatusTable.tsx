@@ -0,0 +1,115 @@
+import React from 'react';
+import {
+  Box,
+  Button,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableRow,
+} from '@mui/material';
+import { FontAwesomeIcon } from '@fortawesome/react-fontawesome';
+import { faStop } from '@fortawesome/free-solid-svg-icons';
+import { Status } from '../../models';
+import BorderedBox from '../atoms/BorderedBox';
+import StatusChip from '../atoms/StatusChip';
+import ConfirmModal from './ConfirmModal';
+
+type Props = {
+  statuses: Status[];
+  name: string;
+  refresh: () => void;
+};
+
+function RunningStatusTable({ statuses, name, refresh }: Props) {
+  const [stopping, setStopping] = React.useState<Status | undefined>(
+    undefined
+  );
+  const onStop = React.useCallback(
+    async (requestId: string) => {
+      const url = `${getConfig().apiURL}/dags/${encodeURIComponent(name)}`;
+      const ret = await fetch(url, {
+        method: 'POST',
+        mode: 'cors',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify({
+          action: 'stop',
+          requestId: requestId,
+        }),
+      });
+      if (!ret.ok) {
+        const e = await ret.text();
+        alert(e || 'Failed to submit');
+      }
+      refresh();
+    },
+    [name, refresh]
+  );
+  if (!statuses.length) {
+    return null;
+  }
+  return (
+    <React.Fragment>
+      <BorderedBox>
+        <Table size="small">
+          <TableHead>
+            <TableRow>
+              <TableCell>Request ID</TableCell>
+              <TableCell>Started At</TableCell>
+              <TableCell>Params</TableCell>
+              <TableCell>Pid</TableCell>
+              <TableCell>Status</TableCell>
+              <TableCell></TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {statuses.map((s) => (
+              <TableRow key={s.RequestId}>
+                <TableCell>{s.RequestId}</TableCell>
+                <TableCell>{s.StartedAt}</TableCell>
+                <TableCell>{s.Params}</TableCell>
+                <TableCell>{s.Pid}</TableCell>
+                <TableCell>
+                  <StatusChip status={s.Status}>{s.StatusText}</StatusChip>
+                </TableCell>
+                <TableCell align="right">
+                  <Button
+                    color="error"
+                    variant="outlined"
+                    size="small"
+                    startIcon={
+                      <span className="icon">
+                        <FontAwesomeIcon icon={faStop} />
+                      </span>
+                    }
+                    onClick={() => setStopping(s)}
+                  >
+                    Stop
+                  </Button>
+                </TableCell>
+              </TableRow>
+            ))}
+          </TableBody>
+        </Table>
+      </BorderedBox>
+      <ConfirmModal
+        title="Confirmation"
+        buttonText="Stop"
+        visible={!!stopping}
+        dismissModal={() => setStopping(undefined)}
+        onSubmit={() => {
+          if (stopping) {
+            onStop(stopping.RequestId);
+          }
+          setStopping(undefined);
+        }}
+      >
+        <Box>Do you really want to stop the run {stopping?.RequestId}?</Box>
+      </ConfirmModal>
+    </React.Fragment>
+  );
+}
+
+export default RunningStatusTable;
@@ -19,7 +19,7 @@ function SearchResult({ results }: Props) {
             <ListItem key={`${result.Name}-${m.LineNumber}`}>
               <Stack direction="column" spacing={1} style={{ width: '100%' }}>
                 {j == 0 ? (
-                  <Link to={`/dags/${encodeURI(result.Name)}/spec`}>
+                  <Link to={`/dags/${encodeURIComponent(result.Name)}/spec`}>
                     <Typography variant="h6">{result.Name}</Typography>
                   </Link>
                 ) : null}
@@ -1,6 +1,7 @@
 import {
   Box,
   Button,
+  MenuItem,
   Modal,
   Stack,
   TextField,
@@ -8,7 +9,7 @@ import {
 } from '@mui/material';
 import React from 'react';
 import { Parameter, parseParams, stringifyParams } from '../../lib/parseParams';
-import { DAG } from '../../models';
+import { DAG, ParamSchema } from '../../models';
 import { Workflow } from '../../models/api';
 
 type Props = {
@@ -59,6 +60,31 @@ function StartDAGModal({ visible, dag, dismissModal, onSubmit }: Props) {
     setParams(parsedParams);
   }, [parsedParams]);
 
+  const schema = 'ParamSchema' in dag ? dag.ParamSchema : undefined;
+  const [values, setValues] = React.useState<Record<string, string>>({});
+
+  React.useEffect(() => {
+    const defaults: Record<string, string> = {};
+    schema?.forEach((p) => {
+      defaults[p.Name] = p.Default || '';
+    });
+    setValues(defaults);
+  }, [schema]);
+
+  const submit = () => {
+    if (schema && schema.length > 0) {
+      onSubmit(
+        stringifyParams(
+          schema
+            .filter((p) => values[p.Name])
+            .map((p) => ({ Name: p.Name, Value: values[p.Name] }))
+        )
+      );
+    } else {
+      onSubmit(stringifyParams(params));
+    }
+  };
+
   return (
     <Modal open={visible} onClose={dismissModal}>
       <Box sx={style}>
@@ -72,81 +98,87 @@ function StartDAGModal({ visible, dag, dismissModal, onSubmit }: Props) {
           spacing={2}
           mt={2}
         >
-          {parsedParams.map((p, i) => {
-            if (p.Name != undefined) {
-              return (
-                <React.Fragment key={i}>
-                  <TextField
-                    label={p.Name}
-                    multiline
-                    placeholder={p.Value}
-                    variant="outlined"
-                    style={{
-                      flex: 0.5,
-                    }}
-                    inputRef={ref}
-                    InputProps={{
-                      value: params.find((pp) => pp.Name == p.Name)?.Value,
-                      onChange: (e) => {
-                        if (p.Name) {
-                          setParams(
-                            params.map((pp) => {
-                              if (pp.Name == p.Name) {
-                                return {
-                                  ...pp,
-                                  Value: e.target.value,
-                                };
-                              } else {
-                                return pp;
-                              }
-                            })
-                          );
-                        }
-                      },
-                    }}
-                  />
-                </React.Fragment>
-              );
-            } else {
-              return (
-                <React.Fragment key={i}>
-                  <TextField
-                    label={`Parameter ${i + 1}`}
-                    multiline
-                    placeholder={p.Value}
-                    variant="outlined"
-                    style={{
-                      flex: 0.5,
-                    }}
-                    inputRef={ref}
-                    InputProps={{
-                      value: params.find((_, j) => i == j)?.Value,
-                      onChange: (e) => {
-                        setParams(
-                          params.map((pp, j) => {
-                            if (j == i) {
-                              return {
-                                ...pp,
-                                Value: e.target.value,
-                              };
-                            } else {
-                              return pp;
+          {schema && schema.length > 0
+            ? schema.map((p) => (
+                <ParamField
+                  key={p.Name}
+                  param={p}
+                  value={values[p.Name] || ''}
+                  onChange={(value) =>
+                    setValues({ ...values, [p.Name]: value })
+                  }
+                />
+              ))
+            : parsedParams.map((p, i) => {
+                if (p.Name != undefined) {
+                  return (
+                    <React.Fragment key={i}>
+                      <TextField
+                        label={p.Name}
+                        multiline
+                        placeholder={p.Value}
+                        variant="outlined"
+                        style={{
+                          flex: 0.5,
+                        }}
+                        inputRef={ref}
+                        InputProps={{
+                          value: params.find((pp) => pp.Name == p.Name)?.Value,
+                          onChange: (e) => {
+                            if (p.Name) {
+                              setParams(
+                                params.map((pp) => {
+                                  if (pp.Name == p.Name) {
+                                    return {
+                                      ...pp,
+                                      Value: e.target.value,
+                                    };
+                                  } else {
+                                    return pp;
+                                  }
+                                })
+                              );
                             }
-                          })
-                        );
-                      },
-                    }}
-                  />
-                </React.Fragment>
-              );
-            }
-          })}
-          <Button
-            variant="outlined"
-            onClick={() => {
-              onSubmit(stringifyParams(params));
-            }}
-          >
+                          },
+                        }}
+                      />
+                    </React.Fragment>
+                  );
+                } else {
+                  return (
+                    <React.Fragment key={i}>
+                      <TextField
+                        label={`Parameter ${i + 1}`}
+                        multiline
+                        placeholder={p.Value}
+                        variant="outlined"
+                        style={{
+                          flex: 0.5,
+                        }}
+                        inputRef={ref}
+                        InputProps={{
+                          value: params.find((_, j) => i == j)?.Value,
+                          onChange: (e) => {
+                            setParams(
+                              params.map((pp, j) => {
+                                if (j == i) {
+                                  return {
+                                    ...pp,
+                                    Value: e.target.value,
+                                  };
+                                } else {
+                                  return pp;
+                                }
+                              })
+                            );
+                          },
+                        }}
+                      />
+                    </React.Fragment>
+                  );
+                }
+              })}
+          <Button variant="outlined" onClick={submit}>
             Start
           </Button>
           <Button variant="outlined" color="error" onClick={dismissModal}>
@@ -158,4 +190,40 @@ function StartDAGModal({ visible, dag, dismissModal, onSubmit }: Props) {
   );
 }
 
+type ParamFieldProps = {
+  param: ParamSchema;
+  value: string;
+  onChange: (value: string) => void;
+};
+
+function ParamField({ param, value, onChange }: ParamFieldProps) {
+  const options =
+    param.Type == 'enum'
+      ? param.Values || []
+      : param.Type == 'bool'
+      ? ['true', 'false']
+      : undefined;
+  return (
+    <TextField
+      label={param.Name}
+      required={param.Required}
+      helperText={param.Description}
+      variant="outlined"
+      select={!!options}
+      type={
+        param.Type == 'int' ? 'number' : param.Type == 'date' ? 'date' : 'text'
+      }
+      InputLabelProps={param.Type == 'date' ? { shrink: true } : undefined}
+      value={value}
+      onChange={(e) => onChange(e.target.value)}
+    >
+      {options?.map((o) => (
+        <MenuItem key={o} value={o}>
+          {o}
+        </MenuItem>
+      ))}
+    </TextField>
+  );
+}
+
 export default StartDAGModal;
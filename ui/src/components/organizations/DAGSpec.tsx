@@ -1,9 +1,10 @@
 import { Box, Button, Stack } from '@mui/material';
 import React from 'react';
-import { GetDAGResponse } from '../../models/api';
+import { APIError, GetDAGResponse, SpecError } from '../../models/api';
 import { DAGContext } from '../../contexts/DAGContext';
 import { DAG, Step } from '../../models';
 import DAGEditor from '../atoms/DAGEditor';
+import DAGDiffEditor from '../atoms/DAGDiffEditor';
 import DAGAttributes from '../molecules/DAGAttributes';
 import DAGDefinition from '../molecules/DAGDefinition';
 import Graph, { FlowchartType } from '../molecules/Graph';
@@ -26,6 +27,11 @@ type Props = {
 function DAGSpec({ data }: Props) {
   const [editing, setEditing] = React.useState(false);
   const [currentValue, setCurrentValue] = React.useState(data.Definition);
+  const [specErrors, setSpecErrors] = React.useState<SpecError[]>([]);
+  const [conflict, setConflict] = React.useState<{
+    spec: string;
+    revision: string;
+  } | null>(null);
   const handlers = getHandlers(data.DAG?.DAG);
   const [cookie, setCookie] = useCookies(['flowchart']);
   const [flowchart, setFlowchart] = React.useState(cookie['flowchart']);
@@ -36,6 +42,50 @@ function DAGSpec({ data }: Props) {
     },
     [setCookie, flowchart, setFlowchart]
   );
+  const save = React.useCallback(
+    async (name: string, revision: string, refresh: () => void) => {
+      const url = `${getConfig().apiURL}/dags/${encodeURIComponent(name)}`;
+      const resp = await fetch(url, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify({
+          action: 'save',
+          value: currentValue,
+          revision: revision,
+        }),
+      });
+      if (resp.ok) {
+        setSpecErrors([]);
+        setConflict(null);
+        setEditing(false);
+        refresh();
+        return;
+      }
+      const e = await resp.text();
+      try {
+        const apiError: APIError = JSON.parse(e);
+        if (resp.status == 409 && apiError.revision) {
+          // The spec was saved by someone else after it was loaded.
+          setSpecErrors([]);
+          setConflict({
+            spec: apiError.spec || '',
+            revision: apiError.revision,
+          });
+          return;
+        }
+        if (apiError.errors?.length) {
+          setSpecErrors(apiError.errors);
+          return;
+        }
+      } catch {
+        // not an API error
+      }
+      alert(e);
+    },
+    [currentValue]
+  );
   if (data.DAG?.DAG == null) {
     return null;
   }
@@ -130,35 +180,25 @@ function DAGSpec({ data }: Props) {
                             <FontAwesomeIcon icon={faFloppyDisk} />
                           </span>
                         }
-                        onClick={async () => {
-                          const url = `${getConfig().apiURL}/dags/${
-                            props.name
-                          }`;
-                          const resp = await fetch(url, {
-                            method: 'POST',
-                            headers: {
-                              'Content-Type': 'application/json',
-                            },
-                            body: JSON.stringify({
-                              action: 'save',
-                              value: currentValue,
-                            }),
-                          });
-                          if (resp.ok) {
-                            setEditing(false);
-                            props.refresh();
-                          } else {
-                            const e = await resp.text();
-                            alert(e);
-                          }
-                        }}
+                        onClick={() =>
+                          save(
+                            props.name,
+                            conflict?.revision || data.Revision || '',
+                            props.refresh
+                          )
+                        }
                       >
-                        Save
+                        {conflict ? 'Overwrite' : 'Save'}
                       </Button>
                       <Button
                         color="error"
                         variant="outlined"
-                        onClick={() => setEditing(false)}
+                        onClick={() => {
+                          setSpecErrors([]);
+                          setConflict(null);
+                          setEditing(false);
+                          props.refresh();
+                        }}
                         sx={{ ml: 2 }}
                         startIcon={
                           <span className="icon">
@@ -189,12 +229,52 @@ function DAGSpec({ data }: Props) {
                 </Stack>
                 {editing ? (
                   <Box sx={{ mt: 2 }}>
-                    <DAGEditor
-                      value={data.Definition}
-                      onChange={(newValue) => {
-                        setCurrentValue(newValue);
-                      }}
-                    ></DAGEditor>
+                    {conflict ? (
+                      <React.Fragment>
+                        <Box sx={{ mb: 2, color: 'warning.main' }}>
+                          The spec was changed by someone else while you were
+                          editing it. The current spec is on the left and your
+                          changes are on the right. Overwrite it with your
+                          changes or cancel to discard them.
+                        </Box>
+                        <DAGDiffEditor
+                          original={conflict.spec}
+                          value={currentValue}
+                          onChange={(newValue) => {
+                            setCurrentValue(newValue);
+                          }}
+                        ></DAGDiffEditor>
+                      </React.Fragment>
+                    ) : (
+                      <DAGEditor
+                        value={data.Definition}
+                        onChange={(newValue) => {
+                          setCurrentValue(newValue);
+                        }}
+                        errors={specErrors}
+                      ></DAGEditor>
+                    )}
+                    {specErrors.length > 0 && (
+                      <Box sx={{ mt: 2 }}>
+                        {specErrors.map((e, i) => (
+                          <Box
+                            key={i}
+                            sx={{
+                              color:
+                                e.severity == 'error'
+                                  ? 'error.main'
+                                  : 'warning.main',
+                              fontFamily: 'monospace',
+                            }}
+                          >
+                            {e.line > 0
+                              ? `Line ${e.line}, column ${e.column}: `
+                              : ''}
+                            {e.message}
+                          </Box>
+                        ))}
+                      </Box>
+                    )}
                   </Box>
                 ) : (
                   <DAGDefinition value={data.Definition} lineNumbers />
@@ -1,9 +1,10 @@
 import React from 'react';
 import { DAGContext } from '../../contexts/DAGContext';
-import { DAGStatus } from '../../models';
+import { DAGStatus, Status } from '../../models';
 import { Handlers, SchedulerStatus } from '../../models';
 import Graph, { FlowchartType } from '../molecules/Graph';
 import NodeStatusTable from '../molecules/NodeStatusTable';
+import RunningStatusTable from '../molecules/RunningStatusTable';
 import DAGStatusOverview from '../molecules/DAGStatusOverview';
 import TimelineChart from '../molecules/TimelineChart';
 import { useDAGPostAPI } from '../../hooks/useDAGPostAPI';
@@ -19,11 +20,12 @@ import { faChartGantt, faShareNodes } from '@fortawesome/free-solid-svg-icons';
 
 type Props = {
   DAG: DAGStatus;
+  runningStatuses?: Status[];
   name: string;
   refresh: () => void;
 };
 
-function DAGStatus({ DAG, name, refresh }: Props) {
+function DAGStatus({ DAG, runningStatuses, name, refresh }: Props) {
   const [modal, setModal] = React.useState(false);
   const [sub, setSub] = React.useState('0');
   const [selectedStep, setSelectedStep] = React.useState<Step | undefined>(
@@ -47,7 +49,12 @@ function DAGStatus({ DAG, name, refresh }: Props) {
   const onSelectStepOnGraph = React.useCallback(
     async (id: string) => {
       const status = DAG.Status?.Status;
-      if (status == SchedulerStatus.Running || status == SchedulerStatus.None) {
+      if (
+        status == SchedulerStatus.Running ||
+        status == SchedulerStatus.None ||
+        status == SchedulerStatus.Queued ||
+        status == SchedulerStatus.Skipped
+      ) {
         return;
       }
       // find the clicked step
@@ -78,6 +85,18 @@ function DAGStatus({ DAG, name, refresh }: Props) {
 
   return (
     <React.Fragment>
+      {runningStatuses && runningStatuses.length > 1 ? (
+        <Box sx={{ mb: 3 }}>
+          <SubTitle>Running</SubTitle>
+          <Box sx={{ mt: 2 }}>
+            <RunningStatusTable
+              statuses={runningStatuses}
+              name={name}
+              refresh={refresh}
+            />
+          </Box>
+        </Box>
+      ) : null}
       <Box>
         <Stack direction="row" justifyContent="space-between">
           <SubTitle>Overview</SubTitle>
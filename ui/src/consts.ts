@@ -11,7 +11,8 @@ export const statusColorMapping: statusColorMapping = {
   [SchedulerStatus.Error]: { backgroundColor: 'red', color: 'white' },
   [SchedulerStatus.Cancel]: { backgroundColor: 'pink' },
   [SchedulerStatus.Success]: { backgroundColor: 'green', color: 'white' },
-  [SchedulerStatus.Skipped_Unused]: { backgroundColor: 'gray', color: 'white' },
+  [SchedulerStatus.Queued]: { backgroundColor: 'khaki' },
+  [SchedulerStatus.Skipped]: { backgroundColor: 'gray', color: 'white' },
 };
 
 export const nodeStatusColorMapping = {
@@ -20,7 +21,8 @@ export const nodeStatusColorMapping = {
   [NodeStatus.Error]: statusColorMapping[SchedulerStatus.Error],
   [NodeStatus.Cancel]: statusColorMapping[SchedulerStatus.Cancel],
   [NodeStatus.Success]: statusColorMapping[SchedulerStatus.Success],
-  [NodeStatus.Skipped]: statusColorMapping[SchedulerStatus.Skipped_Unused],
+  [NodeStatus.Skipped]: { backgroundColor: 'gray', color: 'white' },
+  [NodeStatus.Waiting]: { backgroundColor: 'gold' },
 };
 
 export const stepTabColStyles = [
@@ -10,7 +10,9 @@ type Options = {
 export function useDAGPostAPI(opts: Options) {
   const doPost = React.useCallback(
     async (action: string, step?: string) => {
-      const url = `${getConfig().apiURL}/dags/${opts.name}`;
+      const url = `${getConfig().apiURL}/dags/${encodeURIComponent(
+        opts.name
+      )}`;
       const ret = await fetch(url, {
         method: 'POST',
         mode: 'cors',
@@ -1,4 +1,4 @@
-import { DAG, DAGStatus, Node, NodeStatus, Schedule, SchedulerStatus, StatusFile } from './index';
+import { DAG, DAGStatus, Node, NodeStatus, Schedule, SchedulerStatus, Status, StatusFile } from './index';
 
 export type GetDAGResponse = {
   Title: string;
@@ -6,6 +6,8 @@ export type GetDAGResponse = {
   DAG?: DAGStatus;
   Graph: string;
   Definition: string;
+  Revision?: string;
+  RunningStatuses?: Status[];
   LogData: LogData;
   LogUrl: string;
   StepLog?: LogFile;
@@ -13,6 +15,21 @@ export type GetDAGResponse = {
   Errors: string[];
 };
 
+export type SpecError = {
+  line: number;
+  column: number;
+  severity: string;
+  message: string;
+};
+
+export type APIError = {
+  message: string;
+  detailedMessage: string;
+  errors?: SpecError[];
+  spec?: string;
+  revision?: string;
+};
+
 export type GetSearchResponse = {
   Errors: string[];
   Results: SearchResult[];
@@ -68,6 +85,8 @@ export type Workflow = {
   Description: string;
   Params: string[];
   DefaultParams?: string;
+  MaxConcurrentRuns: number;
+  OverlapPolicy?: string;
   Schedule: Schedule[];
 };
 
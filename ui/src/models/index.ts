@@ -7,7 +7,8 @@ export enum SchedulerStatus {
   Error,
   Cancel,
   Success,
-  Skipped_Unused,
+  Queued,
+  Skipped,
 }
 
 export type Status = {
@@ -25,6 +26,7 @@ export type Status = {
   FinishedAt: string;
   Log: string;
   Params: string;
+  TimedOut?: boolean;
 };
 
 export function Handlers(s: Status) {
@@ -63,12 +65,24 @@ export type DAG = {
   HistRetentionDays: number;
   Preconditions: Condition[];
   MaxActiveRuns: number;
+  MaxConcurrentRuns: number;
+  OverlapPolicy?: string;
   Params: string[];
   DefaultParams?: string;
+  ParamSchema?: ParamSchema[];
   Delay: number;
   MaxCleanUpTime: number;
 };
 
+export type ParamSchema = {
+  Name: string;
+  Type: 'string' | 'int' | 'bool' | 'enum' | 'date';
+  Default?: string;
+  Required?: boolean;
+  Description?: string;
+  Values?: string[];
+};
+
 export type Schedule = {
   Expression: string;
 };
@@ -164,6 +178,7 @@ export enum NodeStatus {
   Cancel,
   Success,
   Skipped,
+  Waiting,
 }
 
 export type Node = {
@@ -173,9 +188,13 @@ export type Node = {
   FinishedAt: string;
   Status: NodeStatus;
   RetryCount: number;
+  NextRetryAt?: string;
+  Outputs?: { [key: string]: string };
   DoneCount: number;
   Error: string;
   StatusText: string;
+  TimedOut?: boolean;
+  PoolWaitTime?: string;
 };
 
 export type StatusFile = {
@@ -195,22 +214,47 @@ export type Step = {
   Output: string;
   Args: string[];
   Depends: string[];
+  TriggerRule?: string;
+  Matrix?: MatrixVar[];
+  GenerateSteps?: boolean;
+  GeneratedBy?: string;
   ContinueOn: ContinueOn;
   RetryPolicy?: RetryPolicy;
   RepeatPolicy: RepeatPolicy;
   MailOnError: boolean;
   Preconditions: Condition[];
+  WaitFor?: WaitFor;
+  Pool?: string;
   Run: string;
   Params: string;
 };
 
 export type RetryPolicy = {
   Limit: number;
+  Interval?: number;
+  Backoff?: number;
+  MaxInterval?: number;
+  Jitter?: number;
+  ExitCodes?: number[];
+};
+
+export type MatrixVar = {
+  Name: string;
+  Values?: string[];
+  Ref?: string;
+};
+
+export type WaitFor = {
+  PollInterval: number;
+  Timeout: number;
 };
 
 export type RepeatPolicy = {
   Repeat: boolean;
   Interval: number;
+  Condition?: Condition;
+  ExitCode?: number[];
+  Limit?: number;
 };
 
 export type ContinueOn = {
@@ -26,11 +26,11 @@ function DAGDetails() {
   const { pathname } = useLocation();
 
   const baseUrl = useMemo(
-    () => `/dags/${encodeURI(params.name!)}`,
+    () => `/dags/${encodeURIComponent(params.name!)}`,
     [params.name]
   );
   const { data, isValidating, mutate } = useSWR<GetDAGResponse>(
-    `/dags/${params.name}?tab=${params.tab ?? ''}&${new URLSearchParams(
+    `/dags/${encodeURIComponent(params.name!)}?tab=${params.tab ?? ''}&${new URLSearchParams(
       window.location.search
     ).toString()}`,
     null,
@@ -118,7 +118,12 @@ function DAGDetails() {
 
         <Box sx={{ mx: 4, flex: 1 }}>
           {tab == 'status' ? (
-            <DAGStatus DAG={data.DAG} name={params.name} refresh={refreshFn} />
+            <DAGStatus
+              DAG={data.DAG}
+              runningStatuses={data.RunningStatuses}
+              name={params.name}
+              refresh={refreshFn}
+            />
           ) : null}
           {tab == 'spec' ? <DAGSpec data={data} /> : null}
           {tab == 'history' ? (